@@ -2,9 +2,11 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -22,7 +24,10 @@ type Config struct {
 	// Server configuration
 	Server struct {
 		Host            string
-		Port            int
+		Port            int // Lower port for object and media messages
+		UpperPort       int // Upper port for running order messages
+		QueryPort       int // MOS 4.0 query port, 0 disables the listener
+		SchemaPort      int // HTTP port serving the searchable schema, 0 disables it
 		ReadTimeout     time.Duration
 		WriteTimeout    time.Duration
 		ShutdownTimeout time.Duration
@@ -43,6 +48,34 @@ type Config struct {
 		HeartbeatInterval time.Duration
 		// Timeout for client connections without heartbeats
 		ClientTimeout time.Duration
+		// Time to wait for the ack of a server-initiated message before
+		// retransmitting it, doubled on each retry
+		AckTimeout time.Duration
+		// Retransmissions of an unacknowledged message before it fails
+		AckRetries int
+		// Machine information reported in listMachInfo
+		Manufacturer string
+		Model        string
+		HWRev        string
+		SWRev        string
+		DOM          string
+		SN           string
+		// MOS protocol revision reported in listMachInfo
+		MOSRev string
+		// Wire encoding: auto, utf-8, ucs-2 (utf-16be) or utf-16le
+		Encoding string
+		// Schema validation of messages: off, warn or strict
+		Validation string
+		// MOS XSD used for validation, res/mosv4.xsd when empty
+		Schema string
+		// URL reported in mosListSearchableSchema, the schema port when empty
+		SearchSchema string
+		// MOS profiles whose messages are rejected and reported as unsupported
+		DisabledProfiles []int
+		// Profile 6 routes to the MOS devices owning items with a foreign mosID
+		Redirects []DeviceRoute
+		// Downstream MOS devices OpenMOS connects to as their NCS
+		Peers []DeviceRoute
 	}
 
 	// Logging configuration
@@ -61,6 +94,17 @@ type Config struct {
 	}
 }
 
+// DeviceRoute addresses a MOS device by its mosID, either to redirect the
+// items it owns or to connect to it as its NCS
+type DeviceRoute struct {
+	MosID string
+	Host  string
+	Port  int // Upper port of the device
+}
+
+// defaultDevicePort is the upper port used when a route names no port
+const defaultDevicePort = 10541
+
 // LoadConfig loads configuration from environment variables and a YAML file if available
 func LoadConfig() (*Config, error) {
 	config := &Config{}
@@ -115,6 +159,15 @@ func LoadConfig() (*Config, error) {
 	if envVal := getEnv("SERVER_PORT", ""); envVal != "" || !yamlLoaded {
 		config.Server.Port = getEnvAsInt("SERVER_PORT", getDefaultInt(config.Server.Port, 10540)) // Default MOS port
 	}
+	if envVal := getEnv("SERVER_UPPER_PORT", ""); envVal != "" || !yamlLoaded {
+		config.Server.UpperPort = getEnvAsInt("SERVER_UPPER_PORT", getDefaultInt(config.Server.UpperPort, 10541)) // Default MOS upper port
+	}
+	if envVal := getEnv("SERVER_QUERY_PORT", ""); envVal != "" || !yamlLoaded {
+		config.Server.QueryPort = getEnvAsInt("SERVER_QUERY_PORT", getDefaultInt(config.Server.QueryPort, 10542)) // Default MOS 4.0 query port
+	}
+	if envVal := getEnv("SERVER_SCHEMA_PORT", ""); envVal != "" || !yamlLoaded {
+		config.Server.SchemaPort = getEnvAsInt("SERVER_SCHEMA_PORT", getDefaultInt(config.Server.SchemaPort, 10543)) // Searchable schema over HTTP
+	}
 	if envVal := getEnv("SERVER_READ_TIMEOUT", ""); envVal != "" || !yamlLoaded {
 		config.Server.ReadTimeout = getEnvAsDuration("SERVER_READ_TIMEOUT", getDefaultDuration(config.Server.ReadTimeout, 5*time.Second))
 	}
@@ -146,6 +199,72 @@ func LoadConfig() (*Config, error) {
 	if envVal := getEnv("MOS_CLIENT_TIMEOUT", ""); envVal != "" || !yamlLoaded {
 		config.MOS.ClientTimeout = getEnvAsDuration("MOS_CLIENT_TIMEOUT", getDefaultDuration(config.MOS.ClientTimeout, 2*time.Minute))
 	}
+	if envVal := getEnv("MOS_ACK_TIMEOUT", ""); envVal != "" || !yamlLoaded {
+		config.MOS.AckTimeout = getEnvAsDuration("MOS_ACK_TIMEOUT", getDefaultDuration(config.MOS.AckTimeout, 10*time.Second))
+	}
+	if envVal := getEnv("MOS_ACK_RETRIES", ""); envVal != "" || !yamlLoaded {
+		config.MOS.AckRetries = getEnvAsInt("MOS_ACK_RETRIES", getDefaultInt(config.MOS.AckRetries, 3))
+	}
+	if envVal := getEnv("MOS_MANUFACTURER", ""); envVal != "" || !yamlLoaded {
+		config.MOS.Manufacturer = getEnv("MOS_MANUFACTURER", getDefaultString(config.MOS.Manufacturer, "Airshift Media"))
+	}
+	if envVal := getEnv("MOS_MODEL", ""); envVal != "" || !yamlLoaded {
+		config.MOS.Model = getEnv("MOS_MODEL", getDefaultString(config.MOS.Model, "OpenMOS"))
+	}
+	if envVal := getEnv("MOS_HW_REV", ""); envVal != "" || !yamlLoaded {
+		config.MOS.HWRev = getEnv("MOS_HW_REV", config.MOS.HWRev)
+	}
+	if envVal := getEnv("MOS_SW_REV", ""); envVal != "" || !yamlLoaded {
+		config.MOS.SWRev = getEnv("MOS_SW_REV", getDefaultString(config.MOS.SWRev, config.App.Version))
+	}
+	if envVal := getEnv("MOS_DOM", ""); envVal != "" || !yamlLoaded {
+		config.MOS.DOM = getEnv("MOS_DOM", config.MOS.DOM)
+	}
+	if envVal := getEnv("MOS_SN", ""); envVal != "" || !yamlLoaded {
+		config.MOS.SN = getEnv("MOS_SN", config.MOS.SN)
+	}
+	if envVal := getEnv("MOS_REV", ""); envVal != "" || !yamlLoaded {
+		config.MOS.MOSRev = getEnv("MOS_REV", getDefaultString(config.MOS.MOSRev, "4.0"))
+	}
+	if envVal := getEnv("MOS_ENCODING", ""); envVal != "" || !yamlLoaded {
+		config.MOS.Encoding = getEnv("MOS_ENCODING", getDefaultString(config.MOS.Encoding, "auto"))
+	}
+	if envVal := getEnv("MOS_VALIDATION", ""); envVal != "" || !yamlLoaded {
+		config.MOS.Validation = getEnv("MOS_VALIDATION", getDefaultString(config.MOS.Validation, "off"))
+	}
+	if envVal := getEnv("MOS_SCHEMA", ""); envVal != "" {
+		config.MOS.Schema = envVal
+	}
+	if envVal := getEnv("MOS_SEARCH_SCHEMA", ""); envVal != "" {
+		config.MOS.SearchSchema = envVal
+	}
+	if envVal := getEnv("MOS_DISABLED_PROFILES", ""); envVal != "" {
+		profiles, err := parseProfiles(envVal)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MOS_DISABLED_PROFILES: %w", err)
+		}
+		config.MOS.DisabledProfiles = profiles
+	}
+	if envVal := getEnv("MOS_REDIRECTS", ""); envVal != "" {
+		redirects, err := parseDeviceRoutes(envVal)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MOS_REDIRECTS: %w", err)
+		}
+		config.MOS.Redirects = redirects
+	}
+	for i := range config.MOS.Redirects {
+		config.MOS.Redirects[i].Port = getDefaultInt(config.MOS.Redirects[i].Port, defaultDevicePort)
+	}
+	if envVal := getEnv("MOS_PEERS", ""); envVal != "" {
+		peers, err := parseDeviceRoutes(envVal)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MOS_PEERS: %w", err)
+		}
+		config.MOS.Peers = peers
+	}
+	for i := range config.MOS.Peers {
+		config.MOS.Peers[i].Port = getDefaultInt(config.MOS.Peers[i].Port, defaultDevicePort)
+	}
 
 	// Logging config
 	if envVal := getEnv("LOG_LEVEL", ""); envVal != "" || !yamlLoaded {
@@ -236,7 +355,10 @@ func GenerateDefaultConfig(filePath string) error {
 
 	// Server config
 	config.Server.Host = "0.0.0.0"
-	config.Server.Port = 10540 // Default MOS port
+	config.Server.Port = 10540       // Default MOS port
+	config.Server.UpperPort = 10541  // Default MOS upper port
+	config.Server.QueryPort = 10542  // Default MOS 4.0 query port
+	config.Server.SchemaPort = 10543 // Searchable schema over HTTP
 	config.Server.ReadTimeout = 5 * time.Second
 	config.Server.WriteTimeout = 5 * time.Second
 	config.Server.ShutdownTimeout = 30 * time.Second
@@ -250,6 +372,14 @@ func GenerateDefaultConfig(filePath string) error {
 	config.MOS.ID = "OpenMOS_Server"
 	config.MOS.HeartbeatInterval = 30 * time.Second
 	config.MOS.ClientTimeout = 2 * time.Minute
+	config.MOS.AckTimeout = 10 * time.Second
+	config.MOS.AckRetries = 3
+	config.MOS.Manufacturer = "Airshift Media"
+	config.MOS.Model = "OpenMOS"
+	config.MOS.SWRev = config.App.Version
+	config.MOS.MOSRev = "4.0"
+	config.MOS.Encoding = "auto"
+	config.MOS.Validation = "off"
 
 	// Logging config
 	config.Logging.Level = "info"
@@ -330,6 +460,51 @@ func getEnvAsFloat(key string, defaultValue float64) float64 {
 	return value
 }
 
+// parseDeviceRoutes parses device routes written as mosID=host[:port],...
+func parseDeviceRoutes(value string) ([]DeviceRoute, error) {
+	var routes []DeviceRoute
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		mosID, address, ok := strings.Cut(entry, "=")
+		if !ok || mosID == "" || address == "" {
+			return nil, fmt.Errorf("route %q is not mosID=host[:port]", entry)
+		}
+
+		route := DeviceRoute{MosID: strings.TrimSpace(mosID), Host: strings.TrimSpace(address)}
+		if host, port, err := net.SplitHostPort(route.Host); err == nil {
+			route.Host = host
+			route.Port, err = strconv.Atoi(port)
+			if err != nil {
+				return nil, fmt.Errorf("route %q has an invalid port", entry)
+			}
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+// parseProfiles parses a comma-separated list of MOS profile numbers
+func parseProfiles(value string) ([]int, error) {
+	var profiles []int
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		profile, err := strconv.Atoi(entry)
+		if err != nil || profile < 0 {
+			return nil, fmt.Errorf("%q is not a profile number", entry)
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, nil
+}
+
 // Default value helpers
 func getDefaultString(current, defaultValue string) string {
 	if current == "" {
@@ -367,5 +542,20 @@ func getDefaultDuration(current, defaultValue time.Duration) time.Duration {
 
 // GetServerAddress returns the full server address string
 func (c *Config) GetServerAddress() string {
-	return fmt.Sprintf("%s:%d", c.Server.Host, c.Server.Port)
+	return c.GetPortAddress(c.Server.Port)
+}
+
+// GetRedirectRoute returns the redirection route for a mosID
+func (c *Config) GetRedirectRoute(mosID string) (DeviceRoute, bool) {
+	for _, route := range c.MOS.Redirects {
+		if route.MosID == mosID {
+			return route, true
+		}
+	}
+	return DeviceRoute{}, false
+}
+
+// GetPortAddress returns the full address string for the given port
+func (c *Config) GetPortAddress(port int) string {
+	return fmt.Sprintf("%s:%d", c.Server.Host, port)
 }
@@ -2,15 +2,22 @@ package events
 
 import (
 	"sync"
+	"time"
 )
 
 // EventType represents the type of event
 type EventType string
 
 const (
-	RunningOrderUpdated EventType = "ro.updated"
-	StoryModified       EventType = "story.modified"
-	ItemChanged         EventType = "item.changed"
+	RunningOrderUpdated         EventType = "ro.updated"
+	RunningOrderDeleted         EventType = "ro.deleted"
+	RunningOrderMetadataUpdated EventType = "ro.metadata.updated"
+	RunningOrderElementChanged  EventType = "ro.element.changed"
+	RunningOrderAirStateChanged EventType = "ro.air.changed"
+	ElementStatusChanged        EventType = "element.status.changed"
+	StoryModified               EventType = "story.modified"
+	ItemChanged                 EventType = "item.changed"
+	ObjectChanged               EventType = "object.changed"
 )
 
 // Event represents an event in the system
@@ -20,6 +27,28 @@ type Event struct {
 	Source  string
 }
 
+// ElementChange is the payload of RunningOrderElementChanged and ItemChanged
+// events. It describes a change to a single story, or to an item when ItemID
+// is set.
+type ElementChange struct {
+	Operation string
+	ROID      string
+	StoryID   string
+	ItemID    string
+}
+
+// StatusChange is the payload of an ElementStatusChanged event. It reports
+// the new status of a story, or of an item when ItemID is set.
+type StatusChange struct {
+	ROID        string
+	StoryID     string
+	ItemID      string
+	ObjID       string
+	ItemChannel string
+	Status      string
+	Time        time.Time
+}
+
 // EventBus is a simple publish-subscribe event bus
 type EventBus struct {
 	subscribers map[EventType][]chan Event
@@ -44,6 +73,22 @@ func (eb *EventBus) Subscribe(eventType EventType, bufferSize int) <-chan Event
 	return ch
 }
 
+// Unsubscribe removes a subscriber registered with Subscribe and closes its
+// channel
+func (eb *EventBus) Unsubscribe(eventType EventType, subscriber <-chan Event) {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+
+	subscribers := eb.subscribers[eventType]
+	for i, ch := range subscribers {
+		if ch == subscriber {
+			eb.subscribers[eventType] = append(subscribers[:i:i], subscribers[i+1:]...)
+			close(ch)
+			return
+		}
+	}
+}
+
 // Publish sends an event to all subscribers of that event type
 func (eb *EventBus) Publish(event Event) {
 	eb.mu.RLock()
@@ -0,0 +1,22 @@
+package events
+
+import "testing"
+
+func TestUnsubscribe(t *testing.T) {
+	bus := NewEventBus()
+	kept := bus.Subscribe(ObjectChanged, 1)
+	removed := bus.Subscribe(ObjectChanged, 1)
+
+	bus.Unsubscribe(ObjectChanged, removed)
+	if _, ok := <-removed; ok {
+		t.Error("unsubscribed channel is still open")
+	}
+	if n := len(bus.subscribers[ObjectChanged]); n != 1 {
+		t.Fatalf("%d subscribers left, want 1", n)
+	}
+
+	bus.Publish(Event{Type: ObjectChanged, Payload: "OBJ1"})
+	if event := <-kept; event.Payload != "OBJ1" {
+		t.Errorf("Payload = %v, want OBJ1", event.Payload)
+	}
+}
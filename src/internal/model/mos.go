@@ -6,24 +6,49 @@ import (
 
 // MOSObject represents the lowest level media object in the MOS hierarchy
 type MOSObject struct {
-	ID          string            `bson:"_id" json:"id"`                // Unique MOS Object ID
-	ObjectType  string            `bson:"objectType" json:"objectType"` // Type of object (e.g., VIDEO, AUDIO, GRAPHIC)
-	Slug        string            `bson:"slug" json:"slug"`             // Human-readable name
-	Duration    int               `bson:"duration" json:"duration"`     // Duration in seconds
+	ID          string            `bson:"_id" json:"id"`                            // Unique MOS Object ID
+	ObjectType  string            `bson:"objectType" json:"objectType"`             // Type of object (e.g., VIDEO, AUDIO, GRAPHIC)
+	Slug        string            `bson:"slug" json:"slug"`                         // Human-readable name
+	Duration    int               `bson:"duration" json:"duration"`                 // Duration in seconds
+	Frames      int               `bson:"frames,omitempty" json:"frames,omitempty"` // Duration in time base units (objDur)
 	TimeBase    int               `bson:"timeBase,omitempty" json:"timeBase,omitempty"`
 	Status      StatusType        `bson:"status" json:"status"`
 	ObjectID    string            `bson:"objectID,omitempty" json:"objectID,omitempty"`
 	MediaID     string            `bson:"mediaID,omitempty" json:"mediaID,omitempty"`
 	MosAbstract string            `bson:"mosAbstract,omitempty" json:"mosAbstract,omitempty"`
+	Group       string            `bson:"group,omitempty" json:"group,omitempty"`
+	Revision    int               `bson:"revision" json:"revision"`
+	Air         string            `bson:"air,omitempty" json:"air,omitempty"` // READY or NOT READY (objAir)
+	Paths       []ObjectPath      `bson:"paths,omitempty" json:"paths,omitempty"`
+	Description string            `bson:"description,omitempty" json:"description,omitempty"`
+	CreatedBy   string            `bson:"createdBy,omitempty" json:"createdBy,omitempty"`
+	ChangedBy   string            `bson:"changedBy,omitempty" json:"changedBy,omitempty"`
 	Metadata    map[string]string `bson:"metadata,omitempty" json:"metadata,omitempty"`
 	CreatedAt   time.Time         `bson:"createdAt" json:"createdAt"`
 	UpdatedAt   time.Time         `bson:"updatedAt" json:"updatedAt"`
 }
 
+// Object path types
+const (
+	PathMedia    = "PATH"
+	PathProxy    = "PROXY"
+	PathMetadata = "METADATA"
+)
+
+// ObjectPath is a location of an object's media, proxy or metadata
+type ObjectPath struct {
+	Type            string `bson:"type" json:"type"` // PATH, PROXY or METADATA
+	TechDescription string `bson:"techDescription,omitempty" json:"techDescription,omitempty"`
+	URL             string `bson:"url" json:"url"`
+}
+
 // Item represents a single item within a story
 type Item struct {
 	ID                string            `bson:"_id" json:"id"`                                // Unique Item ID
+	ItemID            string            `bson:"itemID,omitempty" json:"itemID,omitempty"`     // MOS item ID, unique within the story
 	ObjectID          string            `bson:"objectID,omitempty" json:"objectID,omitempty"` // Reference to MOS Object
+	MosID             string            `bson:"mosID,omitempty" json:"mosID,omitempty"`       // MOS device owning the object
+	Channel           string            `bson:"channel,omitempty" json:"channel,omitempty"`
 	Slug              string            `bson:"slug" json:"slug"`
 	Duration          int               `bson:"duration" json:"duration"` // Duration in seconds
 	EditorialDuration int               `bson:"editorialDuration,omitempty" json:"editorialDuration,omitempty"`
@@ -48,22 +73,52 @@ type Story struct {
 	PreviousID     string            `bson:"previousID,omitempty" json:"previousID,omitempty"` // Previous story ID for linked list
 	NextID         string            `bson:"nextID,omitempty" json:"nextID,omitempty"`         // Next story ID for linked list
 	Presenter      string            `bson:"presenter,omitempty" json:"presenter,omitempty"`
+	PresenterRR    string            `bson:"presenterRR,omitempty" json:"presenterRR,omitempty"` // Presenter read rate
+	Body           []StoryParagraph  `bson:"body,omitempty" json:"body,omitempty"`               // Script sent with roStorySend
 	Metadata       map[string]string `bson:"metadata,omitempty" json:"metadata,omitempty"`
 	CreatedAt      time.Time         `bson:"createdAt" json:"createdAt"`
 	UpdatedAt      time.Time         `bson:"updatedAt" json:"updatedAt"`
 }
 
+// Types of the elements of a story paragraph
+const (
+	StoryText        = "TEXT"         // Text read by the presenter
+	StoryInstruction = "PI"           // Producer instruction, not read on air
+	StoryPresenter   = "PRESENTER"    // Change of presenter
+	StoryReadRate    = "PRESENTER_RR" // Presenter read rate
+	StoryItemRef     = "ITEM"         // Item embedded in the script
+)
+
+// StoryParagraph is a paragraph of a story script
+type StoryParagraph struct {
+	Elements []StoryElement `bson:"elements" json:"elements"`
+}
+
+// StoryElement is a run of text, a producer instruction, a presenter change or
+// a reference to an item of the story, in script order
+type StoryElement struct {
+	Type   string `bson:"type" json:"type"`
+	Text   string `bson:"text,omitempty" json:"text,omitempty"`
+	ItemID string `bson:"itemID,omitempty" json:"itemID,omitempty"` // MOS item ID of an ITEM element
+}
+
 // RunningOrder represents the top-level running order (collection of stories)
 type RunningOrder struct {
-	ID           string            `bson:"_id" json:"id"`      // Unique Running Order ID
-	MosID        string            `bson:"mosID" json:"mosID"` // MOS ID for this running order
+	ID           string            `bson:"_id" json:"id"`                          // Unique Running Order ID
+	MosID        string            `bson:"mosID" json:"mosID"`                     // MOS ID for this running order
+	NcsID        string            `bson:"ncsID,omitempty" json:"ncsID,omitempty"` // NCS that owns this running order
 	Slug         string            `bson:"slug" json:"slug"`
 	Status       StatusType        `bson:"status" json:"status"`
 	Duration     int               `bson:"duration" json:"duration"`                             // Total duration in seconds
 	FirstStoryID string            `bson:"firstStoryID,omitempty" json:"firstStoryID,omitempty"` // First story ID for linked list
 	LastStoryID  string            `bson:"lastStoryID,omitempty" json:"lastStoryID,omitempty"`   // Last story ID for linked list
-	AirTime      *time.Time        `bson:"airTime,omitempty" json:"airTime,omitempty"`
+	AirTime      *time.Time        `bson:"airTime,omitempty" json:"airTime,omitempty"`           // Editorial start (roEdStart)
+	EdDuration   int               `bson:"edDuration,omitempty" json:"edDuration,omitempty"`     // Editorial duration in seconds (roEdDur)
+	Trigger      string            `bson:"trigger,omitempty" json:"trigger,omitempty"`           // MANUAL, TIMED or CHAINED (roTrigger)
+	MacroIn      string            `bson:"macroIn,omitempty" json:"macroIn,omitempty"`
+	MacroOut     string            `bson:"macroOut,omitempty" json:"macroOut,omitempty"`
 	Channel      string            `bson:"channel,omitempty" json:"channel,omitempty"`
+	ReadyToAir   bool              `bson:"readyToAir" json:"readyToAir"` // Set by roReadyToAir, locks destructive edits
 	Metadata     map[string]string `bson:"metadata,omitempty" json:"metadata,omitempty"`
 	Version      int               `bson:"version" json:"version"`
 	CreatedBy    string            `bson:"createdBy,omitempty" json:"createdBy,omitempty"`
@@ -13,6 +13,9 @@ const (
 	// StatusActive indicates the element is currently active
 	StatusActive StatusType = "ACTIVE"
 
+	// StatusPaused indicates the element was paused while active
+	StatusPaused StatusType = "PAUSED"
+
 	// StatusCompleted indicates the element has been successfully completed
 	StatusCompleted StatusType = "COMPLETED"
 
@@ -62,7 +62,7 @@ func (r *MongoItemRepository) Get(ctx context.Context, id string) (*model.Item,
 	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&item)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, fmt.Errorf("item not found: %s", id)
+			return nil, fmt.Errorf("item %w: %s", ErrNotFound, id)
 		}
 		return nil, fmt.Errorf("failed to get item: %w", err)
 	}
@@ -91,7 +91,7 @@ func (r *MongoItemRepository) Delete(ctx context.Context, id string) error {
 	}
 
 	if result.DeletedCount == 0 {
-		return fmt.Errorf("item not found: %s", id)
+		return fmt.Errorf("item %w: %s", ErrNotFound, id)
 	}
 
 	return nil
@@ -4,12 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
 	"time"
 
 	"airshift/openmos/internal/db"
 	"airshift/openmos/internal/model"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -62,7 +64,7 @@ func (r *MongoObjectRepository) Get(ctx context.Context, id string) (*model.MOSO
 	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&obj)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, fmt.Errorf("object not found: %s", id)
+			return nil, fmt.Errorf("object %w: %s", ErrNotFound, id)
 		}
 		return nil, fmt.Errorf("failed to get object: %w", err)
 	}
@@ -91,7 +93,7 @@ func (r *MongoObjectRepository) Delete(ctx context.Context, id string) error {
 	}
 
 	if result.DeletedCount == 0 {
-		return fmt.Errorf("object not found: %s", id)
+		return fmt.Errorf("object %w: %s", ErrNotFound, id)
 	}
 
 	return nil
@@ -114,3 +116,108 @@ func (r *MongoObjectRepository) List(ctx context.Context) ([]*model.MOSObject, e
 
 	return objects, nil
 }
+
+// Search returns one page of the MOS objects matching a query and the total number of matches
+func (r *MongoObjectRepository) Search(ctx context.Context, query ObjectQuery) ([]*model.MOSObject, int, error) {
+	filter, err := objectFilter(query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count objects: %w", err)
+	}
+
+	sort := bson.D{{Key: "createdAt", Value: -1}}
+	if len(query.Sort) > 0 {
+		sort = bson.D{}
+		for _, key := range query.Sort {
+			direction := 1
+			if key.Descending {
+				direction = -1
+			}
+			sort = append(sort, bson.E{Key: key.Field, Value: direction})
+		}
+	}
+
+	opts := options.Find().SetSort(sort).SetSkip(int64(query.Skip))
+	if query.Limit > 0 {
+		opts.SetLimit(int64(query.Limit))
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search objects: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var objects []*model.MOSObject
+	err = cursor.All(ctx, &objects)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to decode objects: %w", err)
+	}
+
+	return objects, int(total), nil
+}
+
+// objectFilter builds the MongoDB filter for an object query
+func objectFilter(query ObjectQuery) (bson.M, error) {
+	var clauses []bson.M
+
+	if query.Text != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(query.Text), Options: "i"}
+		clauses = append(clauses, bson.M{"$or": []bson.M{
+			{"slug": pattern},
+			{"mosAbstract": pattern},
+			{"description": pattern},
+		}})
+	}
+
+	var groups []bson.M
+	for _, group := range query.Groups {
+		conditions := make([]bson.M, 0, len(group))
+		for _, condition := range group {
+			clause, err := conditionFilter(condition)
+			if err != nil {
+				return nil, err
+			}
+			conditions = append(conditions, clause)
+		}
+		if len(conditions) > 0 {
+			groups = append(groups, bson.M{"$and": conditions})
+		}
+	}
+	if len(groups) > 0 {
+		clauses = append(clauses, bson.M{"$or": groups})
+	}
+
+	if len(clauses) == 0 {
+		return bson.M{}, nil
+	}
+	return bson.M{"$and": clauses}, nil
+}
+
+// conditionFilter builds the MongoDB filter for a single field comparison
+func conditionFilter(condition ObjectCondition) (bson.M, error) {
+	operators := map[string]string{
+		OpEqual:        "$eq",
+		OpNotEqual:     "$ne",
+		OpLess:         "$lt",
+		OpLessEqual:    "$lte",
+		OpGreater:      "$gt",
+		OpGreaterEqual: "$gte",
+	}
+
+	if condition.Operator == OpContains {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(fmt.Sprint(condition.Value)), Options: "i"}
+		return bson.M{condition.Field: pattern}, nil
+	}
+
+	operator, ok := operators[condition.Operator]
+	if !ok {
+		return nil, fmt.Errorf("unsupported operator %q", condition.Operator)
+	}
+
+	return bson.M{condition.Field: bson.M{operator: condition.Value}}, nil
+}
@@ -2,10 +2,14 @@ package repository
 
 import (
 	"context"
+	"errors"
 
 	"airshift/openmos/internal/model"
 )
 
+// ErrNotFound is wrapped by the errors returned for missing documents
+var ErrNotFound = errors.New("not found")
+
 // RunningOrderRepository defines operations for running orders
 type RunningOrderRepository interface {
 	// Create creates a new running order
@@ -76,6 +80,47 @@ type ObjectRepository interface {
 
 	// List returns all MOS objects
 	List(ctx context.Context) ([]*model.MOSObject, error)
+
+	// Search returns one page of the objects matching a query and the total
+	// number of matches
+	Search(ctx context.Context, query ObjectQuery) ([]*model.MOSObject, int, error)
+}
+
+// Comparison operators of an ObjectCondition
+const (
+	OpEqual        = "="
+	OpNotEqual     = "!="
+	OpLess         = "<"
+	OpLessEqual    = "<="
+	OpGreater      = ">"
+	OpGreaterEqual = ">="
+	OpContains     = "contains"
+)
+
+// ObjectQuery selects a page of objects from the catalog
+type ObjectQuery struct {
+	// Text is matched case-insensitively against slug, abstract and description
+	Text string
+	// Groups of conditions: conditions are ANDed within a group, groups are ORed
+	Groups [][]ObjectCondition
+	// Sort keys in priority order; newest objects first when empty
+	Sort []ObjectSort
+	// Skip and Limit select the page; a zero Limit returns all matches
+	Skip  int
+	Limit int
+}
+
+// ObjectCondition compares a stored object field with a value
+type ObjectCondition struct {
+	Field    string
+	Operator string
+	Value    interface{}
+}
+
+// ObjectSort orders search results by a stored object field
+type ObjectSort struct {
+	Field      string
+	Descending bool
 }
 
 // Repository combines all repositories
@@ -63,7 +63,7 @@ func (r *MongoRunningOrderRepository) Get(ctx context.Context, id string) (*mode
 	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&ro)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, fmt.Errorf("running order not found: %s", id)
+			return nil, fmt.Errorf("running order %w: %s", ErrNotFound, id)
 		}
 		return nil, fmt.Errorf("failed to get running order: %w", err)
 	}
@@ -81,7 +81,7 @@ func (r *MongoRunningOrderRepository) Update(ctx context.Context, ro *model.Runn
 	err := r.collection.FindOne(ctx, bson.M{"_id": ro.ID}).Decode(&current)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return fmt.Errorf("running order not found: %s", ro.ID)
+			return fmt.Errorf("running order %w: %s", ErrNotFound, ro.ID)
 		}
 		return fmt.Errorf("failed to get current running order: %w", err)
 	}
@@ -108,7 +108,7 @@ func (r *MongoRunningOrderRepository) Delete(ctx context.Context, id string) err
 	}
 
 	if result.DeletedCount == 0 {
-		return fmt.Errorf("running order not found: %s", id)
+		return fmt.Errorf("running order %w: %s", ErrNotFound, id)
 	}
 
 	return nil
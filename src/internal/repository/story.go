@@ -62,7 +62,7 @@ func (r *MongoStoryRepository) Get(ctx context.Context, id string) (*model.Story
 	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&story)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, fmt.Errorf("story not found: %s", id)
+			return nil, fmt.Errorf("story %w: %s", ErrNotFound, id)
 		}
 		return nil, fmt.Errorf("failed to get story: %w", err)
 	}
@@ -91,7 +91,7 @@ func (r *MongoStoryRepository) Delete(ctx context.Context, id string) error {
 	}
 
 	if result.DeletedCount == 0 {
-		return fmt.Errorf("story not found: %s", id)
+		return fmt.Errorf("story %w: %s", ErrNotFound, id)
 	}
 
 	return nil
@@ -2,14 +2,18 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"airshift/openmos/internal/config"
 	"airshift/openmos/internal/events"
+	"airshift/openmos/internal/model"
 	"airshift/openmos/internal/xml"
 	"airshift/openmos/pkg/logger"
 
@@ -21,25 +25,58 @@ type ClientConnection struct {
 	conn       net.Conn
 	id         string
 	server     *TCPServer // Forward declaration - TCPServer is defined in server.go
+	port       *portListener
 	heartbeat  *xml.HeartbeatMonitor
 	parser     *xml.MessageParser
+	decoder    *xml.WireDecoder
 	closeChan  chan struct{}
 	closeOnce  sync.Once
 	writeMutex sync.Mutex
 	config     *config.Config
+
+	// Envelope state: the NCS ID announced by the client and the
+	// counter used for server-initiated message IDs
+	ncsID     string
+	ncsIDMu   sync.RWMutex
+	messageID atomic.Uint64
+
+	// MOS revision reported by the peer in listMachInfo
+	peerMOSRev string
+	peerMu     sync.RWMutex
+
+	// Set once the client sent mosReqAll and receives object updates
+	objectSubscriber atomic.Bool
+
+	// Running orders the client requested with roReq and receives updates for
+	roSubscriptions   map[string]bool
+	roSubscriptionsMu sync.RWMutex
+
+	// Server-initiated messages awaiting the client's ack
+	outbound *outboundTracker
+
+	// roAck of the change being redirected to other MOS devices
+	redirectAck ackHold
+
+	// Set when OpenMOS dialed the peer as its NCS, together with the message
+	// ID of the last heartbeat sent to it
+	peer        *peerLink
+	heartbeatID atomic.Value
 }
 
-// NewClientConnection creates a new client connection
-func NewClientConnection(conn net.Conn, server *TCPServer, cfg *config.Config) *ClientConnection {
+// NewClientConnection creates a new client connection accepted on the given port
+func NewClientConnection(conn net.Conn, server *TCPServer, cfg *config.Config, port *portListener) *ClientConnection {
 	clientID := fmt.Sprintf("%s", conn.RemoteAddr())
 
 	client := &ClientConnection{
-		conn:      conn,
-		id:        clientID,
-		server:    server,
-		parser:    xml.NewMessageParser(),
-		closeChan: make(chan struct{}),
-		config:    cfg,
+		conn:            conn,
+		id:              clientID,
+		server:          server,
+		port:            port,
+		parser:          xml.NewMessageParser(),
+		decoder:         xml.NewWireDecoder(server.encoding),
+		closeChan:       make(chan struct{}),
+		config:          cfg,
+		roSubscriptions: make(map[string]bool),
 	}
 
 	// Create heartbeat monitor
@@ -51,6 +88,12 @@ func NewClientConnection(conn net.Conn, server *TCPServer, cfg *config.Config) *
 		client.Close,
 	)
 
+	if server.validator != nil {
+		client.parser.SetSchema(server.validator.schema)
+	}
+
+	client.outbound = newOutboundTracker(client, &server.outbound, cfg.MOS.AckTimeout, cfg.MOS.AckRetries)
+
 	return client
 }
 
@@ -63,9 +106,19 @@ func (c *ClientConnection) Start(ctx context.Context) {
 	defer cancelMonitor()
 	go c.heartbeat.Start(monitorCtx)
 
-	// Subscribe to relevant events if event bus is available
-	if c.server.eventBus != nil {
-		roEvents := c.server.eventBus.Subscribe(events.RunningOrderUpdated, 10)
+	// Subscribe to relevant events if event bus is available, until the
+	// connection ends
+	if bus := c.server.eventBus; bus != nil {
+		roEvents := bus.Subscribe(events.RunningOrderUpdated, 10)
+		defer bus.Unsubscribe(events.RunningOrderUpdated, roEvents)
+		roDeleteEvents := bus.Subscribe(events.RunningOrderDeleted, 10)
+		defer bus.Unsubscribe(events.RunningOrderDeleted, roDeleteEvents)
+		roMetadataEvents := bus.Subscribe(events.RunningOrderMetadataUpdated, 10)
+		defer bus.Unsubscribe(events.RunningOrderMetadataUpdated, roMetadataEvents)
+		statusEvents := bus.Subscribe(events.ElementStatusChanged, 10)
+		defer bus.Unsubscribe(events.ElementStatusChanged, statusEvents)
+		objectEvents := bus.Subscribe(events.ObjectChanged, 10)
+		defer bus.Unsubscribe(events.ObjectChanged, objectEvents)
 
 		go func() {
 			for {
@@ -80,6 +133,26 @@ func (c *ClientConnection) Start(ctx context.Context) {
 					}
 					// Send notification to this client
 					c.handleRunningOrderUpdate(ctx, event)
+				case event, ok := <-roDeleteEvents:
+					if !ok {
+						return
+					}
+					c.handleRunningOrderDeleted(ctx, event)
+				case event, ok := <-roMetadataEvents:
+					if !ok {
+						return
+					}
+					c.handleRunningOrderMetadataUpdated(ctx, event)
+				case event, ok := <-statusEvents:
+					if !ok {
+						return
+					}
+					c.handleElementStatusChanged(ctx, event)
+				case event, ok := <-objectEvents:
+					if !ok {
+						return
+					}
+					c.handleObjectChanged(ctx, event)
 				}
 			}
 		}()
@@ -89,6 +162,9 @@ func (c *ClientConnection) Start(ctx context.Context) {
 	span := sentry.StartSpan(ctx, "client_connection")
 	span.SetTag("client_id", c.id)
 	span.SetTag("remote_addr", c.conn.RemoteAddr().String())
+	if c.port != nil {
+		span.SetTag("port", c.port.Name())
+	}
 	defer span.Finish()
 
 	// Read loop
@@ -123,13 +199,23 @@ func (c *ClientConnection) Start(ctx context.Context) {
 				return
 			}
 
-			// Process the data
+			// Process the data, converting from the client's wire encoding
 			if n > 0 {
-				c.parser.AppendData(buffer[:n])
+				c.parser.AppendData(c.decoder.Decode(buffer[:n]))
 
 				// Try to parse and handle complete messages
 				for c.parser.HasCompleteMessage() {
 					message, remaining, err := c.parser.Parse()
+
+					// Messages breaking the schema are parsed, the mode decides their fate
+					var invalid *xml.ValidationError
+					if errors.As(err, &invalid) {
+						if !c.acceptInvalid(message, invalid) {
+							continue
+						}
+						err = nil
+					}
+
 					if err != nil {
 						if err == xml.ErrIncompleteXML {
 							// Wait for more data
@@ -193,39 +279,10 @@ func (c *ClientConnection) trackError(err error, operationType string, details m
 	return err
 }
 
-// handleMessage processes a parsed MOS message
+// handleMessage processes a parsed MOS message through the server's handler registry
 func (c *ClientConnection) handleMessage(ctx context.Context, message xml.MOSMessage) error {
-	// Create a span for this message handling
-	span := sentry.StartSpan(ctx, "handle_message")
-	span.SetTag("message_type", message.GetMessageType())
-	span.SetTag("client_id", c.id)
-	defer span.Finish()
-
-	var err error
-
-	switch msg := message.(type) {
-	case xml.Heartbeat:
-		err = c.handleHeartbeat(ctx, msg)
-	case xml.ReqRunningOrderList:
-		err = c.handleReqRunningOrderList(ctx, msg)
-	case xml.ReqRunningOrder:
-		err = c.handleReqRunningOrder(ctx, msg)
-	case xml.RunningOrderInfo:
-		err = c.handleRunningOrderInfo(ctx, msg)
-	case xml.MOSAck:
-		err = c.handleMOSAck(ctx, msg)
-	case xml.NCSReqStoryAction:
-		err = c.handleNCSReqStoryAction(ctx, msg)
-	default:
-		err = fmt.Errorf("unknown message type: %T", message)
-	}
-
-	if err != nil {
-		span.Status = sentry.SpanStatusInternalError
-		span.SetData("error", err.Error())
-	}
-
-	return err
+	c.recordHeader(message.GetHeader())
+	return c.server.registry.Dispatch(ctx, c, message)
 }
 
 // handleHeartbeat processes a heartbeat message
@@ -235,23 +292,31 @@ func (c *ClientConnection) handleHeartbeat(ctx context.Context, heartbeat xml.He
 	// Record the heartbeat
 	c.heartbeat.RecordHeartbeat()
 
+	// A peer answering our own heartbeat gets no response
+	if c.peer != nil && heartbeat.MessageID != "" && heartbeat.MessageID == c.heartbeatID.Load() {
+		return nil
+	}
+
 	// Send response
-	response, err := c.heartbeat.CreateHeartbeatResponse(heartbeat.RequestID)
+	response := xml.CreateHeartbeatResponse(c.config.MOS.ID, heartbeat.RequestID)
+	response.MOSHeader = c.replyHeader(heartbeat.GetHeader())
+
+	data, err := xml.GenerateMessage(response)
 	if err != nil {
 		return fmt.Errorf("failed to create heartbeat response: %w", err)
 	}
 
-	return c.Write(response)
+	return c.Write(data)
 }
 
-// handleReqRunningOrderList processes a request for running order list
+// handleReqRunningOrderList answers roReqAll with the roListAll of all running orders
 func (c *ClientConnection) handleReqRunningOrderList(ctx context.Context, req xml.ReqRunningOrderList) error {
 	logger.Infof("Received running order list request from client %s", c.id)
 
 	// Get running orders from the server
 	runningOrders, err := c.server.service.ListRunningOrders(ctx)
 	if err != nil {
-		return c.sendErrorAck(req.RequestID, "ERROR", fmt.Sprintf("Failed to list running orders: %v", err))
+		return c.sendErrorAck(req.GetHeader(), req.RequestID, "ERROR", fmt.Sprintf("Failed to list running orders: %v", err))
 	}
 
 	// Convert to ROListItem
@@ -268,6 +333,7 @@ func (c *ClientConnection) handleReqRunningOrderList(ctx context.Context, req xm
 
 	// Create response
 	response := xml.CreateRunningOrderList(c.config.MOS.ID, req.RequestID, items)
+	response.MOSHeader = c.replyHeader(req.GetHeader())
 	data, err := xml.GenerateMessage(response)
 	if err != nil {
 		return fmt.Errorf("failed to generate running order list response: %w", err)
@@ -276,92 +342,76 @@ func (c *ClientConnection) handleReqRunningOrderList(ctx context.Context, req xm
 	return c.Write(data)
 }
 
-// handleReqRunningOrder processes a request for a specific running order
+// handleReqRunningOrder answers roReq with the roList of the running order and
+// subscribes the client to its updates
 func (c *ClientConnection) handleReqRunningOrder(ctx context.Context, req xml.ReqRunningOrder) error {
 	logger.Infof("Received running order request from client %s for RO %s", c.id, req.ROID)
 
-	// Get the running order from the server
-	ro, stories, err := c.server.service.GetRunningOrderWithStories(ctx, req.ROID)
+	response, err := c.buildROList(ctx, req.ROID)
 	if err != nil {
-		return c.sendErrorAck(req.RequestID, "ERROR", fmt.Sprintf("Failed to get running order: %v", err))
-	}
-
-	// Convert to StoryInfo
-	storyInfos := make([]xml.StoryInfo, 0, len(stories))
-	for _, story := range stories {
-		// Get items for this story
-		items, err := c.server.service.GetItemsForStory(ctx, story.ID)
-		if err != nil {
-			logger.Warningf("Failed to get items for story %s: %v", story.ID, err)
-			continue
-		}
-
-		// Convert items
-		itemInfos := make([]xml.ItemInfo, 0, len(items))
-		for _, item := range items {
-			itemInfos = append(itemInfos, xml.ItemInfo{
-				ID:       item.ID,
-				Slug:     item.Slug,
-				Duration: fmt.Sprintf("%d", item.Duration),
-				ObjectID: item.ObjectID,
-			})
-		}
-
-		// Add story info
-		storyInfos = append(storyInfos, xml.StoryInfo{
-			ID:       story.ID,
-			Slug:     story.Slug,
-			Number:   story.Number,
-			Duration: fmt.Sprintf("%d", story.Duration),
-			Items:    itemInfos,
-		})
+		return c.sendErrorAck(req.GetHeader(), req.RequestID, "ERROR", fmt.Sprintf("Failed to get running order: %v", err))
 	}
-
-	// Create response
-	response := xml.CreateRunningOrderInfo(
-		c.config.MOS.ID,
-		req.RequestID,
-		ro.ID,
-		ro.Slug,
-		ro.Channel,
-		"", // EditTime
-		"", // StartTime
-		fmt.Sprintf("%d", ro.Duration),
-		storyInfos,
-	)
+	response.RequestID = req.RequestID
+	response.MOSHeader = c.replyHeader(req.GetHeader())
 
 	data, err := xml.GenerateMessage(response)
 	if err != nil {
 		return fmt.Errorf("failed to generate running order response: %w", err)
 	}
 
+	c.subscribeRunningOrder(req.ROID)
+
 	return c.Write(data)
 }
 
-// handleRunningOrderInfo processes a running order create/update message
+// handleRunningOrderInfo processes a running order create/update message and
+// answers with an roAck listing the status of each story
 func (c *ClientConnection) handleRunningOrderInfo(ctx context.Context, roInfo xml.RunningOrderInfo) error {
 	logger.Infof("Received running order info from client %s for RO %s", c.id, roInfo.ID)
 
 	// Process the running order creation/update
-	err := c.server.service.ProcessRunningOrderInfo(ctx, roInfo)
+	results, err := c.server.service.ProcessRunningOrderInfo(ctx, roInfo)
+	if err != nil {
+		return c.sendROAck(roInfo.GetHeader(), roInfo.RequestID, roInfo.ID, rejectStatus(fmt.Sprintf("Failed to process running order: %v", err), err))
+	}
+
+	return c.sendROAckResults(roInfo.GetHeader(), roInfo.RequestID, roInfo.ID, results, nil)
+}
+
+// handleROReplace processes the replacement of an existing running order
+func (c *ClientConnection) handleROReplace(ctx context.Context, roInfo xml.RunningOrderInfo) error {
+	logger.Infof("Received running order replacement from client %s for RO %s", c.id, roInfo.ID)
+
+	results, err := c.server.service.ReplaceRunningOrder(ctx, roInfo)
 	if err != nil {
-		return c.sendErrorAck(roInfo.RequestID, "ERROR", fmt.Sprintf("Failed to process running order: %v", err))
+		return c.sendROAck(roInfo.GetHeader(), roInfo.RequestID, roInfo.ID, rejectStatus(fmt.Sprintf("Failed to replace running order: %v", err), err))
 	}
 
-	// Send acknowledgment
-	return c.sendSuccessAck(roInfo.RequestID, "Running order processed successfully")
+	return c.sendROAckResults(roInfo.GetHeader(), roInfo.RequestID, roInfo.ID, results, nil)
 }
 
-// handleMOSAck processes an acknowledgment message
+// handleMOSAck processes an acknowledgment message, resolving the
+// server-initiated message it answers
 func (c *ClientConnection) handleMOSAck(ctx context.Context, ack xml.MOSAck) error {
 	logger.Infof("Received acknowledgment from client %s: %s - %s", c.id, ack.Status, ack.StatusDescription)
-	// Just log for now
+	c.outbound.acknowledge(ack)
 	return nil
 }
 
-// sendErrorAck sends an error acknowledgment
-func (c *ClientConnection) sendErrorAck(requestID, status, description string) error {
+// Reply sends a response to the message with the given header
+func (c *ClientConnection) Reply(request xml.MOSHeader, response xml.MOSMessage) error {
+	data, err := xml.GenerateMessage(xml.WithHeader(response, c.replyHeader(request)))
+	if err != nil {
+		return fmt.Errorf("failed to generate %s: %w", response.GetMessageType(), err)
+	}
+
+	return c.Write(data)
+}
+
+// sendErrorAck sends an error acknowledgment in reply to the message with the given header
+func (c *ClientConnection) sendErrorAck(header xml.MOSHeader, requestID, status, description string) error {
 	ack := xml.CreateMOSAck(c.config.MOS.ID, requestID, status, description)
+	ack.MOSHeader = c.replyHeader(header)
 	data, err := xml.GenerateMessage(ack)
 	if err != nil {
 		return fmt.Errorf("failed to generate error ack: %w", err)
@@ -371,15 +421,72 @@ func (c *ClientConnection) sendErrorAck(requestID, status, description string) e
 }
 
 // sendSuccessAck sends a success acknowledgment
-func (c *ClientConnection) sendSuccessAck(requestID, description string) error {
-	return c.sendErrorAck(requestID, "ACK", description)
+func (c *ClientConnection) sendSuccessAck(header xml.MOSHeader, requestID, description string) error {
+	return c.sendErrorAck(header, requestID, "ACK", description)
+}
+
+// recordHeader remembers the NCS ID announced in an incoming message header
+func (c *ClientConnection) recordHeader(header xml.MOSHeader) {
+	if header.NcsID == "" {
+		return
+	}
+
+	c.ncsIDMu.Lock()
+	defer c.ncsIDMu.Unlock()
+	c.ncsID = header.NcsID
+}
+
+// NcsID returns the NCS ID last announced by the client
+func (c *ClientConnection) NcsID() string {
+	c.ncsIDMu.RLock()
+	defer c.ncsIDMu.RUnlock()
+	return c.ncsID
+}
+
+// replyHeader returns the envelope header for a reply, echoing the request's IDs
+func (c *ClientConnection) replyHeader(request xml.MOSHeader) xml.MOSHeader {
+	header := request.Reply(c.headerMosID())
+	if header.NcsID == "" {
+		header.NcsID = c.NcsID()
+	}
+	if header.MessageID == "" {
+		header.MessageID = c.nextMessageID()
+	}
+	return header
+}
+
+// pushHeader returns the envelope header for a server-initiated message
+func (c *ClientConnection) pushHeader() xml.MOSHeader {
+	return xml.MOSHeader{
+		MosID:     c.headerMosID(),
+		NcsID:     c.NcsID(),
+		MessageID: c.nextMessageID(),
+	}
 }
 
-// Write sends data to the client
+// headerMosID returns the mosID of the envelope: the peer device's when
+// OpenMOS acts as its NCS, or else our own
+func (c *ClientConnection) headerMosID() string {
+	if c.peer != nil {
+		return c.peer.route.MosID
+	}
+	return c.config.MOS.ID
+}
+
+// nextMessageID returns a new message ID for server-initiated messages
+func (c *ClientConnection) nextMessageID() string {
+	return strconv.FormatUint(c.messageID.Add(1), 10)
+}
+
+// Write sends data to the client in the client's wire encoding
 func (c *ClientConnection) Write(data []byte) error {
+	c.checkOutgoing(data)
+
 	c.writeMutex.Lock()
 	defer c.writeMutex.Unlock()
 
+	data = xml.EncodeWire(data, c.decoder.Outbound())
+
 	// Set write deadline
 	err := c.conn.SetWriteDeadline(time.Now().Add(c.config.Server.WriteTimeout))
 	if err != nil {
@@ -413,7 +520,11 @@ func (c *ClientConnection) Close() {
 			c.trackError(err, "close", nil)
 		}
 
-		c.server.unregisterClient(c.id)
+		c.outbound.close()
+
+		if c.peer == nil {
+			c.server.unregisterClient(c.id)
+		}
 	})
 }
 
@@ -422,7 +533,8 @@ func (c *ClientConnection) ID() string {
 	return c.id
 }
 
-// handleRunningOrderUpdate sends a running order update notification to the client
+// handleRunningOrderUpdate sends an updated running order to the client when
+// it subscribed to it with roReq
 func (c *ClientConnection) handleRunningOrderUpdate(ctx context.Context, event events.Event) {
 	roID, ok := event.Payload.(string)
 	if !ok {
@@ -430,16 +542,87 @@ func (c *ClientConnection) handleRunningOrderUpdate(ctx context.Context, event e
 		return
 	}
 
+	if c.peer != nil {
+		if !c.peer.mirror {
+			return
+		}
+		if err := c.syncPeerRunningOrder(ctx, roID); err != nil {
+			logger.Errorf("Failed to send RO %s to peer MOS %s: %v", roID, c.peer.route.MosID, err)
+		}
+		return
+	}
+
+	if !c.isSubscribed(roID) {
+		return
+	}
+
 	logger.Infof("Sending running order update notification to client %s for RO %s", c.id, roID)
 
 	// Get the updated running order from the service
-	ro, stories, err := c.server.service.GetRunningOrderWithStories(ctx, roID)
+	response, err := c.buildROList(ctx, roID)
 	if err != nil {
 		logger.Errorf("Failed to get running order %s for notification: %v", roID, err)
 		return
 	}
 
-	// Convert stories to StoryInfo
+	err = c.pushWatched(response, func(err error) {
+		c.runningOrderPushFailed(roID, response, err)
+	})
+	if err != nil {
+		logger.Errorf("Failed to send running order notification to client %s: %v", c.id, err)
+	}
+}
+
+// buildROList converts a stored running order with its stories and items to an roList
+func (c *ClientConnection) buildROList(ctx context.Context, roID string) (xml.ROList, error) {
+	ro, stories, err := c.server.service.GetRunningOrderWithStories(ctx, roID)
+	if err != nil {
+		return xml.ROList{}, err
+	}
+
+	editTime := ""
+	if ro.AirTime != nil {
+		editTime = xml.FormatTime(*ro.AirTime)
+	}
+
+	info := xml.CreateRunningOrderInfo(
+		c.config.MOS.ID,
+		"",
+		ro.ID,
+		ro.Slug,
+		ro.Channel,
+		editTime,
+		ro.Trigger,
+		fmt.Sprintf("%d", ro.Duration),
+		c.buildStoryInfos(ctx, stories),
+	)
+
+	return xml.ROList{RunningOrderInfo: info}, nil
+}
+
+// subscribeRunningOrder registers the client for updates of a running order
+func (c *ClientConnection) subscribeRunningOrder(roID string) {
+	c.roSubscriptionsMu.Lock()
+	defer c.roSubscriptionsMu.Unlock()
+	c.roSubscriptions[roID] = true
+}
+
+// unsubscribeRunningOrder stops updates of a running order to the client
+func (c *ClientConnection) unsubscribeRunningOrder(roID string) {
+	c.roSubscriptionsMu.Lock()
+	defer c.roSubscriptionsMu.Unlock()
+	delete(c.roSubscriptions, roID)
+}
+
+// isSubscribed reports whether the client receives updates of a running order
+func (c *ClientConnection) isSubscribed(roID string) bool {
+	c.roSubscriptionsMu.RLock()
+	defer c.roSubscriptionsMu.RUnlock()
+	return c.roSubscriptions[roID]
+}
+
+// buildStoryInfos converts stories and their items to their MOS message form
+func (c *ClientConnection) buildStoryInfos(ctx context.Context, stories []*model.Story) []xml.StoryInfo {
 	storyInfos := make([]xml.StoryInfo, 0, len(stories))
 	for _, story := range stories {
 		// Get items for this story
@@ -452,12 +635,7 @@ func (c *ClientConnection) handleRunningOrderUpdate(ctx context.Context, event e
 		// Convert items
 		itemInfos := make([]xml.ItemInfo, 0, len(items))
 		for _, item := range items {
-			itemInfos = append(itemInfos, xml.ItemInfo{
-				ID:       item.ID,
-				Slug:     item.Slug,
-				Duration: fmt.Sprintf("%d", item.Duration),
-				ObjectID: item.ObjectID,
-			})
+			itemInfos = append(itemInfos, buildItemInfo(item))
 		}
 
 		storyInfos = append(storyInfos, xml.StoryInfo{
@@ -469,26 +647,22 @@ func (c *ClientConnection) handleRunningOrderUpdate(ctx context.Context, event e
 		})
 	}
 
-	// Create and send the running order update message
-	response := xml.CreateRunningOrderInfo(
-		c.config.MOS.ID,
-		"", // No request ID for push notifications
-		ro.ID,
-		ro.Slug,
-		ro.Channel,
-		"",
-		"",
-		fmt.Sprintf("%d", ro.Duration),
-		storyInfos,
-	)
+	return storyInfos
+}
 
-	data, err := xml.GenerateMessage(response)
-	if err != nil {
-		logger.Errorf("Failed to generate running order notification for client %s: %v", c.id, err)
-		return
+// buildItemInfo converts an item to its MOS message form
+func buildItemInfo(item *model.Item) xml.ItemInfo {
+	itemID := item.ItemID
+	if itemID == "" {
+		itemID = item.ID
 	}
 
-	if err := c.Write(data); err != nil {
-		logger.Errorf("Failed to send running order notification to client %s: %v", c.id, err)
+	return xml.ItemInfo{
+		ID:       itemID,
+		Slug:     item.Slug,
+		Duration: fmt.Sprintf("%d", item.Duration),
+		ObjectID: item.ObjectID,
+		MosID:    item.MosID,
+		Channel:  item.Channel,
 	}
 }
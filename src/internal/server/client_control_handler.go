@@ -0,0 +1,43 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"airshift/openmos/internal/service"
+	"airshift/openmos/internal/xml"
+	"airshift/openmos/pkg/logger"
+)
+
+// handleROCtrl processes a Profile 5 control command
+func (c *ClientConnection) handleROCtrl(ctx context.Context, req xml.ROCtrl) error {
+	command := req.ControlCommand()
+	logger.Infof("Received control command %s from client %s for RO %s story %s item %s",
+		command, c.id, req.ROID, req.StoryID, req.ItemID)
+
+	var at time.Time
+	if req.CtrlTime != "" {
+		var err error
+		at, err = xml.ParseTime(req.CtrlTime)
+		if err != nil {
+			return c.sendROAck(req.GetHeader(), req.RequestID, req.ROID, fmt.Sprintf("Invalid roCtrlTime %q", req.CtrlTime))
+		}
+	}
+
+	results, err := c.server.service.ControlElement(ctx, req.ROID, req.StoryID, req.ItemID, command, at)
+	return c.sendROAckResults(req.GetHeader(), req.RequestID, req.ROID, results, err)
+}
+
+// handleROItemCue processes an item cue by readying the item for playout
+func (c *ClientConnection) handleROItemCue(ctx context.Context, req xml.ROItemCue) error {
+	logger.Infof("Received item cue %s from client %s for RO %s story %s item %s",
+		req.EventType, c.id, req.ROID, req.StoryID, req.ItemID)
+
+	if req.ItemID == "" {
+		return c.sendROAck(req.GetHeader(), req.RequestID, req.ROID, "Missing itemID")
+	}
+
+	results, err := c.server.service.ControlElement(ctx, req.ROID, req.StoryID, req.ItemID, service.CommandReady, time.Time{})
+	return c.sendROAckResults(req.GetHeader(), req.RequestID, req.ROID, results, err)
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"airshift/openmos/internal/xml"
+	"airshift/openmos/pkg/logger"
+)
+
+// handleReqMachInfo answers a machine information request with listMachInfo
+func (c *ClientConnection) handleReqMachInfo(ctx context.Context, req xml.ReqMachInfo) error {
+	logger.Infof("Received machine info request from client %s", c.id)
+
+	response := xml.ListMachInfo{
+		Manufacturer:      c.config.MOS.Manufacturer,
+		Model:             c.config.MOS.Model,
+		HWRev:             c.config.MOS.HWRev,
+		SWRev:             c.config.MOS.SWRev,
+		DOM:               c.config.MOS.DOM,
+		SN:                c.config.MOS.SN,
+		ID:                c.config.MOS.ID,
+		Time:              xml.Now(),
+		OpTime:            xml.FormatTime(c.server.startedAt),
+		MOSRev:            c.config.MOS.MOSRev,
+		SupportedProfiles: xml.CreateSupportedProfiles(deviceType, c.server.reportedProfiles()),
+	}
+	response.MOSHeader = c.replyHeader(req.GetHeader())
+
+	data, err := xml.GenerateMessage(response)
+	if err != nil {
+		return fmt.Errorf("failed to generate machine info response: %w", err)
+	}
+
+	return c.Write(data)
+}
+
+// handleListMachInfo processes machine information sent by the peer
+func (c *ClientConnection) handleListMachInfo(ctx context.Context, info xml.ListMachInfo) error {
+	logger.Infof("Received machine info from client %s: %s %s (%s), MOS revision %s",
+		c.id, info.Manufacturer, info.Model, info.ID, info.MOSRev)
+
+	// Remember the peer revision to pick the message versions sent to it
+	c.peerMu.Lock()
+	defer c.peerMu.Unlock()
+	c.peerMOSRev = info.MOSRev
+
+	return nil
+}
@@ -0,0 +1,306 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"airshift/openmos/internal/events"
+	"airshift/openmos/internal/model"
+	"airshift/openmos/internal/xml"
+	"airshift/openmos/pkg/logger"
+)
+
+// handleMOSObj stores an object description sent by the client
+func (c *ClientConnection) handleMOSObj(ctx context.Context, obj xml.MOSObj) error {
+	logger.Infof("Received object %s from client %s: %s", obj.ObjID, c.id, obj.Status)
+
+	stored, err := c.server.service.StoreObject(ctx, obj)
+	if err != nil {
+		return c.sendObjectAck(obj.GetHeader(), obj.RequestID, obj.ObjID, obj.ObjRev, "NACK", fmt.Sprintf("Failed to store object: %v", err))
+	}
+
+	return c.sendObjectAck(obj.GetHeader(), obj.RequestID, stored.ID, strconv.Itoa(stored.Revision), "ACK", "")
+}
+
+// handleMOSReqObj answers an object request with the stored object
+func (c *ClientConnection) handleMOSReqObj(ctx context.Context, req xml.MOSReqObj) error {
+	logger.Infof("Received object request from client %s for object %s", c.id, req.ObjID)
+
+	obj, err := c.server.service.GetObject(ctx, req.ObjID)
+	if err != nil {
+		return c.sendObjectAck(req.GetHeader(), req.RequestID, req.ObjID, "", "NACK", fmt.Sprintf("Failed to get object: %v", err))
+	}
+
+	response := c.buildMOSObj(obj)
+	response.RequestID = req.RequestID
+	response.MOSHeader = c.replyHeader(req.GetHeader())
+
+	data, err := xml.GenerateMessage(response)
+	if err != nil {
+		return fmt.Errorf("failed to generate object response: %w", err)
+	}
+
+	return c.Write(data)
+}
+
+// handleMOSReqAll sends the object catalog, either as one mosListAll or as a
+// series of mosObj messages, and subscribes the client to object updates
+func (c *ClientConnection) handleMOSReqAll(ctx context.Context, req xml.MOSReqAll) error {
+	logger.Infof("Received request for all objects from client %s, pause %s", c.id, req.Pause)
+
+	pause := 0
+	if req.Pause != "" {
+		var err error
+		pause, err = strconv.Atoi(req.Pause)
+		if err != nil || pause < 0 {
+			return c.sendObjectAck(req.GetHeader(), req.RequestID, "", "", "NACK", fmt.Sprintf("Invalid pause %q", req.Pause))
+		}
+	}
+
+	objects, err := c.server.service.ListObjects(ctx)
+	if err != nil {
+		return c.sendObjectAck(req.GetHeader(), req.RequestID, "", "", "NACK", fmt.Sprintf("Failed to list objects: %v", err))
+	}
+
+	c.objectSubscriber.Store(true)
+
+	if pause == 0 {
+		response := xml.MOSListAll{
+			RequestID: req.RequestID,
+			Timestamp: xml.Now(),
+			Source:    c.config.MOS.ID,
+		}
+		for _, obj := range objects {
+			response.Objects = append(response.Objects, c.buildMOSObj(obj))
+		}
+		response.MOSHeader = c.replyHeader(req.GetHeader())
+
+		data, err := xml.GenerateMessage(response)
+		if err != nil {
+			return fmt.Errorf("failed to generate object list: %w", err)
+		}
+
+		return c.Write(data)
+	}
+
+	err = c.sendObjectAck(req.GetHeader(), req.RequestID, "", "", "ACK", "")
+	if err != nil {
+		return err
+	}
+
+	// Trickle the objects out with the requested pause between them
+	go func() {
+		for i, obj := range objects {
+			if i > 0 {
+				select {
+				case <-ctx.Done():
+					return
+				case <-c.closeChan:
+					return
+				case <-time.After(time.Duration(pause) * time.Second):
+				}
+			}
+
+			if err := c.sendMOSObj(obj); err != nil {
+				logger.Errorf("Failed to send object %s to client %s: %v", obj.ID, c.id, err)
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// handleMOSListAll stores the object catalog sent by the client
+func (c *ClientConnection) handleMOSListAll(ctx context.Context, list xml.MOSListAll) error {
+	logger.Infof("Received %d objects from client %s", len(list.Objects), c.id)
+
+	for _, obj := range list.Objects {
+		if _, err := c.server.service.StoreObject(ctx, obj); err != nil {
+			logger.Warningf("Failed to store object %s from client %s: %v", obj.ObjID, c.id, err)
+		}
+	}
+
+	return nil
+}
+
+// handleObjectChanged pushes a changed object to clients that sent mosReqAll
+func (c *ClientConnection) handleObjectChanged(ctx context.Context, event events.Event) {
+	obj, ok := event.Payload.(*model.MOSObject)
+	if !ok {
+		logger.Warningf("Invalid object in event payload for client %s", c.id)
+		return
+	}
+
+	if !c.objectSubscriber.Load() {
+		return
+	}
+
+	logger.Infof("Sending object %s update to client %s", obj.ID, c.id)
+
+	if err := c.sendMOSObj(obj); err != nil {
+		logger.Errorf("Failed to send object update to client %s: %v", c.id, err)
+	}
+}
+
+// sendMOSObj sends an unsolicited mosObj
+func (c *ClientConnection) sendMOSObj(obj *model.MOSObject) error {
+	_, err := c.Push(c.buildMOSObj(obj))
+	return err
+}
+
+// sendObjectAck sends a mosAck for an object message
+func (c *ClientConnection) sendObjectAck(header xml.MOSHeader, requestID, objID, objRev, status, description string) error {
+	ack := xml.CreateMOSAck(c.config.MOS.ID, requestID, status, description)
+	ack.ObjID = objID
+	ack.ObjRev = objRev
+	ack.MOSHeader = c.replyHeader(header)
+
+	data, err := xml.GenerateMessage(ack)
+	if err != nil {
+		return fmt.Errorf("failed to generate object ack: %w", err)
+	}
+
+	return c.Write(data)
+}
+
+// buildMOSObj converts a stored object to its mosObj form
+func (c *ClientConnection) buildMOSObj(obj *model.MOSObject) xml.MOSObj {
+	message := xml.MOSObj{
+		Timestamp:   xml.Now(),
+		Source:      c.config.MOS.ID,
+		ObjID:       obj.ID,
+		ObjSlug:     obj.Slug,
+		MosAbstract: obj.MosAbstract,
+		ObjGroup:    obj.Group,
+		ObjType:     obj.ObjectType,
+		ObjTB:       strconv.Itoa(obj.TimeBase),
+		ObjRev:      strconv.Itoa(obj.Revision),
+		ObjDur:      strconv.Itoa(obj.Frames),
+		Status:      string(obj.Status),
+		ObjAir:      obj.Air,
+		CreatedBy:   obj.CreatedBy,
+		Created:     xml.FormatTime(obj.CreatedAt),
+		ChangedBy:   obj.ChangedBy,
+		Changed:     xml.FormatTime(obj.UpdatedAt),
+		Description: obj.Description,
+	}
+
+	if len(obj.Paths) > 0 {
+		paths := &xml.ObjPaths{}
+		for _, path := range obj.Paths {
+			objPath := xml.ObjPath{TechDescription: path.TechDescription, URL: path.URL}
+			switch path.Type {
+			case model.PathProxy:
+				paths.ProxyPaths = append(paths.ProxyPaths, objPath)
+			case model.PathMetadata:
+				paths.MetadataPaths = append(paths.MetadataPaths, objPath)
+			default:
+				paths.Paths = append(paths.Paths, objPath)
+			}
+		}
+		message.ObjPaths = paths
+	}
+
+	for _, schema := range sortedKeys(obj.Metadata) {
+		message.ExternalMeta = append(message.ExternalMeta, xml.MosExternalMetadata{
+			MosSchema:  schema,
+			MosPayload: xml.MosPayload{Content: obj.Metadata[schema]},
+		})
+	}
+
+	return message
+}
+
+// handleMOSObjCreate creates a placeholder object and returns its new ID
+func (c *ClientConnection) handleMOSObjCreate(ctx context.Context, req xml.MOSObjCreate) error {
+	logger.Infof("Received object create from client %s: %s", c.id, req.ObjSlug)
+
+	obj, err := c.server.service.CreateObject(ctx, req.ObjectFields)
+	if err != nil {
+		return c.sendObjectAck(req.GetHeader(), req.RequestID, "", "", "NACK", fmt.Sprintf("Failed to create object: %v", err))
+	}
+
+	return c.sendObjectAck(req.GetHeader(), req.RequestID, obj.ID, strconv.Itoa(obj.Revision), "ACK", "")
+}
+
+// handleMOSReqObjAction creates, updates or deletes an object
+func (c *ClientConnection) handleMOSReqObjAction(ctx context.Context, req xml.MOSReqObjAction) error {
+	logger.Infof("Received object action %s from client %s for object %s", req.Operation, c.id, req.ObjID)
+
+	obj, err := c.server.service.ApplyObjectAction(ctx, req)
+	if err != nil {
+		return c.sendObjectAck(req.GetHeader(), req.RequestID, req.ObjID, "", "NACK", fmt.Sprintf("Failed to %s object: %v", req.Operation, err))
+	}
+
+	return c.sendObjectAck(req.GetHeader(), req.RequestID, obj.ID, strconv.Itoa(obj.Revision), "ACK", "")
+}
+
+// handleMOSItemReplace replaces the content of an item owned by the client
+func (c *ClientConnection) handleMOSItemReplace(ctx context.Context, req xml.MOSItemReplace) error {
+	logger.Infof("Received item replace from client %s for RO %s story %s item %s", c.id, req.ROID, req.StoryID, req.Item.ID)
+
+	results, err := c.server.service.ReplaceItemContent(ctx, req.ROID, req.StoryID, req.Item)
+	return c.sendROAckResults(req.GetHeader(), req.RequestID, req.ROID, results, err)
+}
+
+// handleMOSReqSearchableSchema answers with the schema of the searchable object fields
+func (c *ClientConnection) handleMOSReqSearchableSchema(ctx context.Context, req xml.MOSReqSearchableSchema) error {
+	logger.Infof("Received searchable schema request from client %s", c.id)
+
+	response := xml.MOSListSearchableSchema{
+		RequestID: req.RequestID,
+		Timestamp: xml.Now(),
+		Source:    c.config.MOS.ID,
+		Username:  req.Username,
+		MosSchema: c.searchSchemaURL(),
+	}
+	response.MOSHeader = c.replyHeader(req.GetHeader())
+
+	data, err := xml.GenerateMessage(response)
+	if err != nil {
+		return fmt.Errorf("failed to generate searchable schema: %w", err)
+	}
+
+	return c.Write(data)
+}
+
+// handleMOSReqObjList answers a catalog search with one page of matching objects
+func (c *ClientConnection) handleMOSReqObjList(ctx context.Context, req xml.MOSReqObjList) error {
+	logger.Infof("Received object search %s from client %s: %q", req.QueryID, c.id, req.GeneralSearch)
+
+	response := xml.MOSObjList{
+		RequestID: req.RequestID,
+		Timestamp: xml.Now(),
+		Source:    c.config.MOS.ID,
+		Username:  req.Username,
+		QueryID:   req.QueryID,
+	}
+
+	result, err := c.server.service.SearchObjects(ctx, req)
+	if err != nil {
+		response.ListReturnStatus = fmt.Sprintf("Search failed: %v", err)
+	} else {
+		response.ListReturnStart = result.Start
+		response.ListReturnEnd = result.End
+		response.ListReturnTotal = result.Total
+		response.ListReturnStatus = result.Status
+
+		if len(result.Objects) > 0 {
+			response.List = &xml.ObjList{}
+			for _, obj := range result.Objects {
+				response.List.Objects = append(response.List.Objects, c.buildMOSObj(obj))
+			}
+		}
+	}
+	response.MOSHeader = c.replyHeader(req.GetHeader())
+
+	data, err := xml.GenerateMessage(response)
+	if err != nil {
+		return fmt.Errorf("failed to generate object list: %w", err)
+	}
+
+	return c.Write(data)
+}
@@ -0,0 +1,356 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"airshift/openmos/internal/events"
+	"airshift/openmos/internal/model"
+	"airshift/openmos/internal/service"
+	"airshift/openmos/internal/xml"
+	"airshift/openmos/pkg/logger"
+	"airshift/openmos/pkg/utils"
+)
+
+// handleRODelete processes a running order delete message
+func (c *ClientConnection) handleRODelete(ctx context.Context, req xml.RODelete) error {
+	logger.Infof("Received running order delete from client %s for RO %s", c.id, req.ROID)
+
+	err := c.server.service.DeleteRunningOrder(ctx, req.ROID)
+	if err != nil {
+		return c.sendROAck(req.GetHeader(), req.RequestID, req.ROID, rejectStatus(fmt.Sprintf("Failed to delete running order: %v", err), err))
+	}
+
+	return c.sendROAck(req.GetHeader(), req.RequestID, req.ROID, "OK")
+}
+
+// handleROReadyToAir processes an NCS flagging a running order as ready, or
+// not ready, to air
+func (c *ClientConnection) handleROReadyToAir(ctx context.Context, req xml.ROReadyToAir) error {
+	logger.Infof("Received ready to air from client %s for RO %s: %s", c.id, req.ROID, req.ROAir)
+
+	var ready bool
+	switch req.ROAir {
+	case "READY":
+		ready = true
+	case "NOT READY":
+		ready = false
+	default:
+		return c.sendROAck(req.GetHeader(), req.RequestID, req.ROID, fmt.Sprintf("Invalid roAir value %q", req.ROAir))
+	}
+
+	err := c.server.service.SetReadyToAir(ctx, req.ROID, ready)
+	if err != nil {
+		return c.sendROAck(req.GetHeader(), req.RequestID, req.ROID, fmt.Sprintf("Failed to set ready to air: %v", err))
+	}
+
+	return c.sendROAck(req.GetHeader(), req.RequestID, req.ROID, "OK")
+}
+
+// handleROMetadataReplace processes a running order metadata replacement
+func (c *ClientConnection) handleROMetadataReplace(ctx context.Context, req xml.ROMetadataReplace) error {
+	logger.Infof("Received running order metadata replace from client %s for RO %s", c.id, req.ROID)
+
+	err := c.server.service.ReplaceRunningOrderMetadata(ctx, req)
+	if err != nil {
+		return c.sendROAck(req.GetHeader(), req.RequestID, req.ROID, fmt.Sprintf("Failed to replace running order metadata: %v", err))
+	}
+
+	return c.sendROAck(req.GetHeader(), req.RequestID, req.ROID, "OK")
+}
+
+// handleROStoryInsert processes the insertion of stories before a target story
+func (c *ClientConnection) handleROStoryInsert(ctx context.Context, req xml.ROStoryInsert) error {
+	logger.Infof("Received story insert from client %s for RO %s before story %s", c.id, req.ROID, req.StoryID)
+
+	results, err := c.server.service.InsertStories(ctx, req.ROID, req.StoryID, req.Stories)
+	return c.sendROAckResults(req.GetHeader(), req.RequestID, req.ROID, results, err)
+}
+
+// handleROStoryAppend processes stories appended to a running order
+func (c *ClientConnection) handleROStoryAppend(ctx context.Context, req xml.ROStoryAppend) error {
+	logger.Infof("Received story append from client %s for RO %s", c.id, req.ROID)
+
+	results, err := c.server.service.AppendStories(ctx, req.ROID, req.Stories)
+	return c.sendROAckResults(req.GetHeader(), req.RequestID, req.ROID, results, err)
+}
+
+// handleROStoryReplace processes the replacement of a story
+func (c *ClientConnection) handleROStoryReplace(ctx context.Context, req xml.ROStoryReplace) error {
+	logger.Infof("Received story replace from client %s for RO %s story %s", c.id, req.ROID, req.StoryID)
+
+	results, err := c.server.service.ReplaceStory(ctx, req.ROID, req.StoryID, req.Stories)
+	return c.sendROAckResults(req.GetHeader(), req.RequestID, req.ROID, results, err)
+}
+
+// handleROStoryMove processes moving a single story
+func (c *ClientConnection) handleROStoryMove(ctx context.Context, req xml.ROStoryMove) error {
+	logger.Infof("Received story move from client %s for RO %s", c.id, req.ROID)
+
+	if len(req.StoryIDs) == 0 {
+		return c.sendROAck(req.GetHeader(), req.RequestID, req.ROID, "Missing storyID")
+	}
+
+	targetID := ""
+	if len(req.StoryIDs) > 1 {
+		targetID = req.StoryIDs[1]
+	}
+
+	results, err := c.server.service.MoveStories(ctx, req.ROID, req.StoryIDs[:1], targetID)
+	return c.sendROAckResults(req.GetHeader(), req.RequestID, req.ROID, results, err)
+}
+
+// handleROStoryMoveMultiple processes moving several stories before the last listed story
+func (c *ClientConnection) handleROStoryMoveMultiple(ctx context.Context, req xml.ROStoryMoveMultiple) error {
+	logger.Infof("Received multiple story move from client %s for RO %s", c.id, req.ROID)
+
+	if len(req.StoryIDs) < 2 {
+		return c.sendROAck(req.GetHeader(), req.RequestID, req.ROID, "At least one story and a target storyID are required")
+	}
+
+	last := len(req.StoryIDs) - 1
+	results, err := c.server.service.MoveStories(ctx, req.ROID, req.StoryIDs[:last], req.StoryIDs[last])
+	return c.sendROAckResults(req.GetHeader(), req.RequestID, req.ROID, results, err)
+}
+
+// handleROStorySwap processes swapping two stories
+func (c *ClientConnection) handleROStorySwap(ctx context.Context, req xml.ROStorySwap) error {
+	logger.Infof("Received story swap from client %s for RO %s", c.id, req.ROID)
+
+	if len(req.StoryIDs) != 2 {
+		return c.sendROAck(req.GetHeader(), req.RequestID, req.ROID, "Exactly two storyIDs are required")
+	}
+
+	results, err := c.server.service.SwapStories(ctx, req.ROID, req.StoryIDs[0], req.StoryIDs[1])
+	return c.sendROAckResults(req.GetHeader(), req.RequestID, req.ROID, results, err)
+}
+
+// handleROStoryDelete processes the deletion of stories
+func (c *ClientConnection) handleROStoryDelete(ctx context.Context, req xml.ROStoryDelete) error {
+	logger.Infof("Received story delete from client %s for RO %s", c.id, req.ROID)
+
+	results, err := c.server.service.DeleteStories(ctx, req.ROID, req.StoryIDs)
+	return c.sendROAckResults(req.GetHeader(), req.RequestID, req.ROID, results, err)
+}
+
+// handleROItemInsert processes the insertion of items into a story
+func (c *ClientConnection) handleROItemInsert(ctx context.Context, req xml.ROItemInsert) error {
+	logger.Infof("Received item insert from client %s for RO %s story %s before item %s", c.id, req.ROID, req.StoryID, req.ItemID)
+
+	results, err := c.server.service.InsertItems(ctx, req.ROID, req.StoryID, req.ItemID, req.Items)
+	return c.sendROAckResults(req.GetHeader(), req.RequestID, req.ROID, results, err)
+}
+
+// handleROItemReplace processes the replacement of an item
+func (c *ClientConnection) handleROItemReplace(ctx context.Context, req xml.ROItemReplace) error {
+	logger.Infof("Received item replace from client %s for RO %s story %s item %s", c.id, req.ROID, req.StoryID, req.ItemID)
+
+	results, err := c.server.service.ReplaceItem(ctx, req.ROID, req.StoryID, req.ItemID, req.Items)
+	return c.sendROAckResults(req.GetHeader(), req.RequestID, req.ROID, results, err)
+}
+
+// handleROItemMoveMultiple processes moving several items before the last listed item
+func (c *ClientConnection) handleROItemMoveMultiple(ctx context.Context, req xml.ROItemMoveMultiple) error {
+	logger.Infof("Received multiple item move from client %s for RO %s story %s", c.id, req.ROID, req.StoryID)
+
+	if len(req.ItemIDs) < 2 {
+		return c.sendROAck(req.GetHeader(), req.RequestID, req.ROID, "At least one item and a target itemID are required")
+	}
+
+	last := len(req.ItemIDs) - 1
+	results, err := c.server.service.MoveItems(ctx, req.ROID, req.StoryID, req.ItemIDs[:last], req.ItemIDs[last])
+	return c.sendROAckResults(req.GetHeader(), req.RequestID, req.ROID, results, err)
+}
+
+// handleROItemDelete processes the deletion of items from a story
+func (c *ClientConnection) handleROItemDelete(ctx context.Context, req xml.ROItemDelete) error {
+	logger.Infof("Received item delete from client %s for RO %s story %s", c.id, req.ROID, req.StoryID)
+
+	results, err := c.server.service.DeleteItems(ctx, req.ROID, req.StoryID, req.ItemIDs)
+	return c.sendROAckResults(req.GetHeader(), req.RequestID, req.ROID, results, err)
+}
+
+// handleROElementAction processes a story or item change sent as roElementAction
+func (c *ClientConnection) handleROElementAction(ctx context.Context, req xml.ROElementAction) error {
+	logger.Infof("Received element action %s from client %s for RO %s", req.Operation, c.id, req.ROID)
+
+	results, err := c.server.service.ApplyElementAction(ctx, req)
+	return c.sendROAckResults(req.GetHeader(), req.RequestID, req.ROID, results, err)
+}
+
+// handleROAck processes a running order acknowledgment, logging each element
+// the client did not accept
+func (c *ClientConnection) handleROAck(ctx context.Context, ack xml.ROAck) error {
+	logger.Infof("Received running order acknowledgment from client %s for RO %s: %s", c.id, ack.ROID, ack.ROStatus)
+	c.outbound.acknowledge(ack)
+
+	for _, element := range ack.Elements {
+		if element.Status == service.ElementOK {
+			continue
+		}
+		logger.Warningf("Client %s reported %s for RO %s story %s item %s object %s", c.id, element.Status, ack.ROID, element.StoryID, element.ItemID, element.ObjID)
+	}
+
+	return nil
+}
+
+// sendROAck sends a running order acknowledgment in reply to the message with
+// the given header, cutting the status to the length the schema allows
+func (c *ClientConnection) sendROAck(header xml.MOSHeader, requestID, roID, status string) error {
+	ack := xml.CreateROAck(c.config.MOS.ID, requestID, roID, service.TruncateStatus(status))
+	ack.MOSHeader = c.replyHeader(header)
+
+	return c.writeROAck(ack)
+}
+
+// sendROAckResults sends a running order acknowledgment carrying the status of
+// each element touched by a change, or the error that rejected the change
+func (c *ClientConnection) sendROAckResults(header xml.MOSHeader, requestID, roID string, results []service.ElementStatus, err error) error {
+	if err != nil {
+		return c.sendROAck(header, requestID, roID, rejectStatus(err.Error(), err))
+	}
+
+	elements := make([]xml.ROAckElement, 0, len(results))
+	for _, result := range results {
+		elements = append(elements, xml.CreateROAckElement(result.StoryID, result.ItemID, result.ObjID, result.ItemChannel, result.Status))
+	}
+
+	ack := xml.CreateROAckResults(c.config.MOS.ID, requestID, roID, service.SummarizeStatus(results), elements)
+	ack.MOSHeader = c.replyHeader(header)
+
+	return c.writeROAck(ack)
+}
+
+// writeROAck sends a running order acknowledgment, unless it is held to merge
+// the results of the devices the change is redirected to
+func (c *ClientConnection) writeROAck(ack xml.ROAck) error {
+	if c.heldROAck(ack) {
+		return nil
+	}
+
+	data, err := xml.GenerateMessage(ack)
+	if err != nil {
+		return fmt.Errorf("failed to generate running order ack: %w", err)
+	}
+
+	return c.Write(data)
+}
+
+// rejectStatus returns the roStatus for a rejected change. Changes refused by
+// the air-lock are reported as an explicit NACK.
+func rejectStatus(description string, err error) string {
+	if errors.Is(err, service.ErrRunningOrderOnAir) {
+		return service.FailureStatus(err)
+	}
+	return description
+}
+
+// handleRunningOrderDeleted tells a subscribed client that a running order
+// has been deleted and ends its subscription
+func (c *ClientConnection) handleRunningOrderDeleted(ctx context.Context, event events.Event) {
+	roID, ok := event.Payload.(string)
+	if !ok {
+		logger.Warningf("Invalid running order ID in event payload for client %s", c.id)
+		return
+	}
+
+	if !c.isSubscribed(roID) {
+		return
+	}
+	c.unsubscribeRunningOrder(roID)
+
+	logger.Infof("Sending running order delete notification to client %s for RO %s", c.id, roID)
+
+	message := xml.CreateRODelete(c.config.MOS.ID, "", roID)
+	err := c.pushWatched(message, func(err error) {
+		c.runningOrderPushFailed(roID, message, err)
+	})
+	if err != nil {
+		logger.Errorf("Failed to send running order delete notification to client %s: %v", c.id, err)
+	}
+}
+
+// handleRunningOrderMetadataUpdated sends the updated running order header to
+// a subscribed client
+func (c *ClientConnection) handleRunningOrderMetadataUpdated(ctx context.Context, event events.Event) {
+	roID, ok := event.Payload.(string)
+	if !ok {
+		logger.Warningf("Invalid running order ID in event payload for client %s", c.id)
+		return
+	}
+
+	if !c.isSubscribed(roID) {
+		return
+	}
+
+	logger.Infof("Sending running order metadata notification to client %s for RO %s", c.id, roID)
+
+	ro, err := c.server.service.GetRunningOrder(ctx, roID)
+	if err != nil {
+		logger.Errorf("Failed to get running order %s for notification: %v", roID, err)
+		return
+	}
+
+	message := c.buildROMetadataReplace(ro)
+	err = c.pushWatched(message, func(err error) {
+		c.runningOrderPushFailed(roID, message, err)
+	})
+	if err != nil {
+		logger.Errorf("Failed to send running order metadata notification to client %s: %v", c.id, err)
+	}
+}
+
+// runningOrderPushFailed handles a running order update the client rejected
+// or never acknowledged. A client rejecting an update no longer follows the
+// running order, so its subscription ends.
+func (c *ClientConnection) runningOrderPushFailed(roID string, message xml.MOSMessage, err error) {
+	if errors.Is(err, ErrMessageRejected) && c.isSubscribed(roID) {
+		logger.Warningf("Client %s rejected %s for RO %s, ending its subscription: %v", c.id, message.GetMessageType(), roID, err)
+		c.unsubscribeRunningOrder(roID)
+		return
+	}
+	logger.Warningf("Client %s did not accept %s for RO %s: %v", c.id, message.GetMessageType(), roID, err)
+}
+
+// buildROMetadataReplace converts a stored running order header to a roMetadataReplace message
+func (c *ClientConnection) buildROMetadataReplace(ro *model.RunningOrder) xml.ROMetadataReplace {
+	message := xml.ROMetadataReplace{
+		Timestamp: xml.Now(),
+		Source:    c.config.MOS.ID,
+		ROID:      ro.ID,
+		Slug:      ro.Slug,
+		Channel:   ro.Channel,
+		Trigger:   ro.Trigger,
+		MacroIn:   ro.MacroIn,
+		MacroOut:  ro.MacroOut,
+	}
+
+	if ro.AirTime != nil {
+		message.EdStart = xml.FormatTime(*ro.AirTime)
+	}
+	if ro.EdDuration > 0 {
+		message.EdDur = utils.FormatDuration(ro.EdDuration)
+	}
+
+	// Emit metadata in a stable order
+	for _, schema := range sortedKeys(ro.Metadata) {
+		message.ExternalMeta = append(message.ExternalMeta, xml.MosExternalMetadata{
+			MosSchema:  schema,
+			MosPayload: xml.MosPayload{Content: ro.Metadata[schema]},
+		})
+	}
+
+	return message
+}
+
+// sortedKeys returns the keys of a metadata map in a stable order
+func sortedKeys(metadata map[string]string) []string {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"airshift/openmos/internal/events"
+	"airshift/openmos/internal/model"
+	"airshift/openmos/internal/service"
+	"airshift/openmos/internal/xml"
+	"airshift/openmos/pkg/logger"
+)
+
+// mosStatuses maps element statuses to the status values defined by MOS
+var mosStatuses = map[model.StatusType]string{
+	model.StatusPending:   "NOT READY",
+	model.StatusReady:     "READY",
+	model.StatusActive:    "PLAY",
+	model.StatusPaused:    "STOP",
+	model.StatusCompleted: "STOP",
+	model.StatusSkipped:   "NOT READY",
+	model.StatusError:     "NOT READY",
+}
+
+// handleElementStatusChanged reports a story or item status change to the
+// client when it is the NCS owning the running order
+func (c *ClientConnection) handleElementStatusChanged(ctx context.Context, event events.Event) {
+	change, ok := event.Payload.(events.StatusChange)
+	if !ok {
+		logger.Warningf("Invalid status change in event payload for client %s", c.id)
+		return
+	}
+
+	// Status messages belong on the running order port, and peer devices
+	// report status rather than receive it
+	if (c.port != nil && c.port.Name() != PortUpper) || c.peer != nil {
+		return
+	}
+
+	ro, err := c.server.service.GetRunningOrder(ctx, change.ROID)
+	if err != nil {
+		logger.Errorf("Failed to get running order %s for status notification: %v", change.ROID, err)
+		return
+	}
+	if ro.NcsID != "" && ro.NcsID != c.NcsID() {
+		return
+	}
+
+	message := c.buildStatusMessage(change)
+
+	logger.Infof("Sending %s to client %s for RO %s story %s item %s",
+		message.GetMessageType(), c.id, change.ROID, change.StoryID, change.ItemID)
+
+	if _, err := c.Push(message); err != nil {
+		logger.Errorf("Failed to send status notification to client %s: %v", c.id, err)
+	}
+}
+
+// handleDeviceStatus relays a status message from a peer device to the NCS
+// clients owning the running order and acknowledges it
+func (c *ClientConnection) handleDeviceStatus(ctx context.Context, roID string, message xml.MOSMessage) error {
+	logger.Infof("Received %s from client %s for RO %s", message.GetMessageType(), c.id, roID)
+
+	mosID := message.GetHeader().MosID
+	if c.peer != nil {
+		mosID = c.peer.route.MosID
+	}
+	c.server.relayStatus(ctx, mosID, roID, message)
+
+	return c.sendROAck(message.GetHeader(), "", roID, service.ElementOK)
+}
+
+// buildStatusMessage converts a status change to roElementStat for MOS 3 and
+// later peers, or to roStoryStat / roItemStat for MOS 2.x peers
+func (c *ClientConnection) buildStatusMessage(change events.StatusChange) xml.MOSMessage {
+	status, ok := mosStatuses[model.StatusType(change.Status)]
+	if !ok {
+		status = change.Status
+	}
+	statusTime := xml.FormatTime(change.Time)
+
+	if majorRevision(c.mosRevision()) >= 3 {
+		element := xml.ElementStory
+		if change.ItemID != "" {
+			element = xml.ElementItem
+		}
+
+		message := xml.ROElementStat{
+			Element:     element,
+			Timestamp:   xml.Now(),
+			Source:      c.config.MOS.ID,
+			ROID:        change.ROID,
+			StoryID:     change.StoryID,
+			ItemID:      change.ItemID,
+			ObjID:       change.ObjID,
+			ItemChannel: change.ItemChannel,
+			Status:      status,
+			Time:        statusTime,
+		}
+		return message
+	}
+
+	if change.ItemID == "" {
+		message := xml.ROStoryStat{
+			Timestamp: xml.Now(),
+			Source:    c.config.MOS.ID,
+			ROID:      change.ROID,
+			StoryID:   change.StoryID,
+			Status:    status,
+			Time:      statusTime,
+		}
+		return message
+	}
+
+	message := xml.ROItemStat{
+		Timestamp:   xml.Now(),
+		Source:      c.config.MOS.ID,
+		ROID:        change.ROID,
+		StoryID:     change.StoryID,
+		ItemID:      change.ItemID,
+		ObjID:       change.ObjID,
+		ItemChannel: change.ItemChannel,
+		Status:      status,
+		Time:        statusTime,
+	}
+	return message
+}
+
+// mosRevision returns the MOS revision reported by the peer, or the
+// configured revision when the peer has not sent listMachInfo
+func (c *ClientConnection) mosRevision() string {
+	c.peerMu.RLock()
+	defer c.peerMu.RUnlock()
+
+	if c.peerMOSRev != "" {
+		return c.peerMOSRev
+	}
+	return c.config.MOS.MOSRev
+}
+
+// majorRevision returns the major version of a MOS revision such as "2.8.5",
+// or 0 when it cannot be parsed
+func majorRevision(revision string) int {
+	major, _, _ := strings.Cut(strings.TrimSpace(revision), ".")
+	value, err := strconv.Atoi(major)
+	if err != nil {
+		return 0
+	}
+	return value
+}
@@ -2,9 +2,9 @@ package server
 
 import (
 	"context"
-	"encoding/xml"
 	"fmt"
 
+	"airshift/openmos/internal/service"
 	mosxml "airshift/openmos/internal/xml"
 	"airshift/openmos/pkg/logger"
 
@@ -31,37 +31,36 @@ func (c *ClientConnection) handleNCSReqStoryAction(ctx context.Context, ncsReq m
 
 		// Log and send error response
 		logger.Errorf("Error processing story action: %v", err)
-		return c.sendNCSErrorAck("ERROR", fmt.Sprintf("Failed to process story action: %v", err))
+		return c.sendNCSErrorAck(ncsReq.GetHeader(), "ERROR", fmt.Sprintf("Failed to process story action: %v", err))
 	}
 
 	// Send success response
-	return c.sendNCSSuccessAck("Story action processed successfully")
+	return c.sendNCSSuccessAck(ncsReq.GetHeader(), "Story action processed successfully")
 }
 
-// sendNCSErrorAck sends an error acknowledgment for NCS requests
-func (c *ClientConnection) sendNCSErrorAck(status, description string) error {
+// handleROStorySend stores a story body sent by the NCS
+func (c *ClientConnection) handleROStorySend(ctx context.Context, req mosxml.ROStorySend) error {
+	logger.Infof("Received story body from client %s for RO %s story %s", c.id, req.ROID, req.StoryID)
+
+	err := c.server.service.StoreStory(ctx, req)
+	if err != nil {
+		return c.sendROAck(req.GetHeader(), req.RequestID, req.ROID, fmt.Sprintf("Failed to store story %s: %v", req.StoryID, err))
+	}
+
+	return c.sendROAck(req.GetHeader(), req.RequestID, req.ROID, service.ElementOK)
+}
+
+// sendNCSErrorAck sends an error acknowledgment in reply to the NCS request with the given header
+func (c *ClientConnection) sendNCSErrorAck(header mosxml.MOSHeader, status, description string) error {
 	// Create NCS acknowledgment
 	ack := mosxml.NCSAck{
 		Status:            status,
 		StatusDescription: description,
 	}
-
-	// Wrap it in a MOS message
-	mosMessage := struct {
-		XMLName   xml.Name      `xml:"mos"`
-		MosID     string        `xml:"mosID"`
-		NcsID     string        `xml:"ncsID"`
-		MessageID string        `xml:"messageID"`
-		NCSAck    mosxml.NCSAck `xml:"ncsAck"`
-	}{
-		MosID:     c.config.MOS.ID,
-		NcsID:     "ncs.client.com", // Should come from client context
-		MessageID: "1",              // Should be generated
-		NCSAck:    ack,
-	}
+	ack.MOSHeader = c.replyHeader(header)
 
 	// Generate the XML
-	data, err := xml.Marshal(mosMessage)
+	data, err := mosxml.GenerateMessage(ack)
 	if err != nil {
 		return fmt.Errorf("failed to generate NCS ack: %w", err)
 	}
@@ -71,6 +70,6 @@ func (c *ClientConnection) sendNCSErrorAck(status, description string) error {
 }
 
 // sendNCSSuccessAck sends a success acknowledgment for NCS requests
-func (c *ClientConnection) sendNCSSuccessAck(description string) error {
-	return c.sendNCSErrorAck("ACK", description)
+func (c *ClientConnection) sendNCSSuccessAck(header mosxml.MOSHeader, description string) error {
+	return c.sendNCSErrorAck(header, "ACK", description)
 }
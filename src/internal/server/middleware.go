@@ -0,0 +1,115 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"airshift/openmos/internal/service"
+	"airshift/openmos/internal/xml"
+	"airshift/openmos/pkg/logger"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// defaultMiddleware is the chain every message runs through, outermost first
+func (s *TCPServer) defaultMiddleware() []Middleware {
+	return []Middleware{
+		traceMiddleware,
+		portMiddleware,
+		profileMiddleware(s.config.MOS.DisabledProfiles),
+		onAirOverrideMiddleware,
+		redirectMiddleware,
+	}
+}
+
+// traceMiddleware wraps the handling of a message in a Sentry span
+func traceMiddleware(route Route, next HandlerFunc) HandlerFunc {
+	return func(ctx context.Context, c *ClientConnection, message xml.MOSMessage) error {
+		span := sentry.StartSpan(ctx, "handle_message")
+		span.SetTag("message_type", route.MessageType)
+		span.SetTag("client_id", c.id)
+		defer span.Finish()
+
+		err := next(span.Context(), c, message)
+		if err != nil {
+			span.Status = sentry.SpanStatusInternalError
+			span.SetData("error", err.Error())
+		}
+		return err
+	}
+}
+
+// portMiddleware rejects messages that belong on another MOS port. Peer
+// connections are not bound to a port and accept every message.
+func portMiddleware(route Route, next HandlerFunc) HandlerFunc {
+	return func(ctx context.Context, c *ClientConnection, message xml.MOSMessage) error {
+		if c.port != nil && !route.acceptedOn(c.port.Name()) {
+			logger.Warningf("Rejecting %s from client %s on %s port", route.MessageType, c.id, c.port.Name())
+			return c.sendErrorAck(message.GetHeader(), "", "NACK",
+				fmt.Sprintf("Message %s is not accepted on the %s port", route.MessageType, c.port.Name()))
+		}
+		return next(ctx, c, message)
+	}
+}
+
+// profileMiddleware rejects the messages of disabled MOS profiles
+func profileMiddleware(disabled []int) Middleware {
+	off := make(map[int]bool)
+	for _, profile := range disabled {
+		off[profile] = true
+	}
+
+	return func(route Route, next HandlerFunc) HandlerFunc {
+		if !off[route.Profile] {
+			return next
+		}
+		return func(ctx context.Context, c *ClientConnection, message xml.MOSMessage) error {
+			logger.Warningf("Rejecting %s from client %s: profile %d is disabled", route.MessageType, c.id, route.Profile)
+			return c.sendErrorAck(message.GetHeader(), "", "NACK",
+				fmt.Sprintf("Message %s belongs to disabled MOS profile %d", route.MessageType, route.Profile))
+		}
+	}
+}
+
+// onAirOverrideMiddleware lets changes marked as on-air overrides bypass the
+// air-lock
+func onAirOverrideMiddleware(route Route, next HandlerFunc) HandlerFunc {
+	return func(ctx context.Context, c *ClientConnection, message xml.MOSMessage) error {
+		if o, ok := message.(interface{ IsOnAirOverride() bool }); ok && o.IsOnAirOverride() {
+			ctx = service.WithOnAirOverride(ctx)
+		}
+		return next(ctx, c, message)
+	}
+}
+
+// redirectMiddleware forwards running order changes to the devices owning
+// redirected items once the change was applied locally. The roAck of the
+// change is held until the devices answered or the redirector's reply timeout
+// expired, so the client receives a single roAck merging the local and the
+// devices' results.
+func redirectMiddleware(route Route, next HandlerFunc) HandlerFunc {
+	return func(ctx context.Context, c *ClientConnection, message xml.MOSMessage) error {
+		// Resolve the devices owning redirected items before the change is applied
+		redirects := c.server.redirector.Route(ctx, message)
+		if len(redirects) == 0 {
+			return next(ctx, c, message)
+		}
+
+		c.holdROAck()
+		err := next(ctx, c, message)
+		ack := c.releaseROAck()
+
+		if err != nil {
+			if ack != nil {
+				if writeErr := c.writeROAck(*ack); writeErr != nil {
+					logger.Errorf("Failed to send running order ack to client %s: %v", c.id, writeErr)
+				}
+			}
+			return err
+		}
+
+		results := c.server.redirector.Send(c, redirects)
+		go c.server.redirector.reply(c, ack, results)
+		return nil
+	}
+}
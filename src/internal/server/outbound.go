@@ -0,0 +1,412 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"airshift/openmos/internal/service"
+	"airshift/openmos/internal/xml"
+	"airshift/openmos/pkg/logger"
+)
+
+// defaultAckTimeout is used when no ack timeout is configured
+const defaultAckTimeout = 10 * time.Second
+
+// outboundStatsInterval is how often the outbound message counters are logged
+const outboundStatsInterval = 5 * time.Minute
+
+var (
+	// ErrAckTimeout is returned for a message that was never acknowledged
+	ErrAckTimeout = errors.New("no acknowledgment received")
+	// ErrMessageRejected is returned for a message the peer answered with a NACK
+	ErrMessageRejected = errors.New("message rejected")
+	// ErrConnectionClosed is returned for messages pending when the connection closed
+	ErrConnectionClosed = errors.New("connection closed")
+)
+
+// OutboundStats counts the server-initiated messages and their outcomes
+type OutboundStats struct {
+	Sent          uint64
+	Acknowledged  uint64
+	Rejected      uint64
+	Retransmitted uint64
+	TimedOut      uint64
+	Dropped       uint64 // Pending when the connection closed
+}
+
+// String formats the counters for the logs
+func (s OutboundStats) String() string {
+	return fmt.Sprintf("%d sent, %d acknowledged, %d rejected, %d retransmitted, %d timed out, %d dropped",
+		s.Sent, s.Acknowledged, s.Rejected, s.Retransmitted, s.TimedOut, s.Dropped)
+}
+
+// outboundCounters holds the server-wide outbound message counters
+type outboundCounters struct {
+	sent          atomic.Uint64
+	acknowledged  atomic.Uint64
+	rejected      atomic.Uint64
+	retransmitted atomic.Uint64
+	timedOut      atomic.Uint64
+	dropped       atomic.Uint64
+}
+
+// snapshot returns the current counter values
+func (c *outboundCounters) snapshot() OutboundStats {
+	return OutboundStats{
+		Sent:          c.sent.Load(),
+		Acknowledged:  c.acknowledged.Load(),
+		Rejected:      c.rejected.Load(),
+		Retransmitted: c.retransmitted.Load(),
+		TimedOut:      c.timedOut.Load(),
+		Dropped:       c.dropped.Load(),
+	}
+}
+
+// outboundMessage is a server-initiated message awaiting the peer's ack
+type outboundMessage struct {
+	messageID   string
+	messageType string
+	roID        string
+	data        []byte // Generated once so retransmissions keep the message ID
+	attempts    int
+	sentAt      time.Time
+	timer       *time.Timer
+	done        chan PushResult
+}
+
+// PushResult is the outcome of a server-initiated message
+type PushResult struct {
+	Ack xml.MOSMessage // The roAck or mosAck answering the message, if any
+	Err error
+}
+
+// outboundTracker is the pending-request table of a connection. Messages are
+// retransmitted with a doubling timeout until the peer acknowledges them or
+// the retries run out.
+type outboundTracker struct {
+	client   *ClientConnection
+	counters *outboundCounters
+	timeout  time.Duration
+	retries  int
+
+	pending map[string]*outboundMessage
+	closed  bool
+	mu      sync.Mutex
+}
+
+// newOutboundTracker creates the tracker of a connection
+func newOutboundTracker(client *ClientConnection, counters *outboundCounters, timeout time.Duration, retries int) *outboundTracker {
+	if timeout <= 0 {
+		timeout = defaultAckTimeout
+	}
+	if retries < 0 {
+		retries = 0
+	}
+
+	return &outboundTracker{
+		client:   client,
+		counters: counters,
+		timeout:  timeout,
+		retries:  retries,
+		pending:  make(map[string]*outboundMessage),
+	}
+}
+
+// Push sends a server-initiated message and tracks it until the client acks
+// it. The returned channel receives the client's ack, with the error that
+// made the message fail if it was rejected or never acknowledged.
+func (c *ClientConnection) Push(message xml.MOSMessage) (<-chan PushResult, error) {
+	header := c.pushHeader()
+	message = xml.WithHeader(message, header)
+
+	data, err := xml.GenerateMessage(message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate %s: %w", message.GetMessageType(), err)
+	}
+
+	pending, err := c.outbound.add(header.MessageID, message, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.Write(data); err != nil {
+		c.outbound.resolve(pending.messageID, nil, err)
+		return nil, err
+	}
+
+	return pending.done, nil
+}
+
+// pushWatched sends a server-initiated message like Push, and calls onFailure
+// if the client rejects it or never acknowledges it
+func (c *ClientConnection) pushWatched(message xml.MOSMessage, onFailure func(error)) error {
+	done, err := c.Push(message)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		result := <-done
+		if result.Err != nil && !errors.Is(result.Err, ErrConnectionClosed) {
+			onFailure(result.Err)
+		}
+	}()
+	return nil
+}
+
+// add records a message before it is first sent
+func (t *outboundTracker) add(messageID string, message xml.MOSMessage, data []byte) (*outboundMessage, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return nil, ErrConnectionClosed
+	}
+
+	pending := &outboundMessage{
+		messageID:   messageID,
+		messageType: message.GetMessageType(),
+		roID:        messageROID(message),
+		data:        data,
+		sentAt:      time.Now(),
+		done:        make(chan PushResult, 1),
+	}
+	pending.timer = time.AfterFunc(t.timeout, func() {
+		t.expire(messageID)
+	})
+	t.pending[messageID] = pending
+	t.counters.sent.Add(1)
+
+	return pending, nil
+}
+
+// expire retransmits a message whose ack timed out, or fails it once the
+// retries are exhausted
+func (t *outboundTracker) expire(messageID string) {
+	t.mu.Lock()
+	pending, ok := t.pending[messageID]
+	if !ok {
+		t.mu.Unlock()
+		return
+	}
+
+	if pending.attempts >= t.retries {
+		t.mu.Unlock()
+		t.resolve(messageID, nil, fmt.Errorf("%w for %s %s after %d attempts", ErrAckTimeout, pending.messageType, messageID, pending.attempts+1))
+		return
+	}
+
+	pending.attempts++
+	pending.timer = time.AfterFunc(t.timeout<<pending.attempts, func() {
+		t.expire(messageID)
+	})
+	t.mu.Unlock()
+
+	logger.Warningf("Retransmitting %s %s to client %s (attempt %d)", pending.messageType, messageID, t.client.id, pending.attempts+1)
+	t.counters.retransmitted.Add(1)
+
+	if err := t.client.Write(pending.data); err != nil {
+		t.resolve(messageID, nil, err)
+	}
+}
+
+// acknowledge resolves the message an incoming roAck or mosAck answers. It
+// reports whether the ack matched a pending message.
+func (t *outboundTracker) acknowledge(ack xml.MOSMessage) bool {
+	var roID, status string
+	switch msg := ack.(type) {
+	case xml.ROAck:
+		roID, status = msg.ROID, msg.ROStatus
+	case xml.MOSAck:
+		status = msg.Status
+		if msg.StatusDescription != "" {
+			status = fmt.Sprintf("%s: %s", msg.Status, msg.StatusDescription)
+		}
+	default:
+		return false
+	}
+
+	messageID, ok := t.match(ack.GetHeader().MessageID, roID)
+	if !ok {
+		return false
+	}
+
+	var err error
+	if !acceptedStatus(status) {
+		err = fmt.Errorf("%w: %s", ErrMessageRejected, status)
+	}
+	t.resolve(messageID, ack, err)
+
+	return true
+}
+
+// match returns the pending message an ack answers: the one with the echoed
+// message ID, or else the oldest message for the running order when the peer
+// does not echo message IDs
+func (t *outboundTracker) match(messageID, roID string) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.pending[messageID]; ok && messageID != "" {
+		return messageID, true
+	}
+	if roID == "" {
+		return "", false
+	}
+
+	var oldest *outboundMessage
+	for _, pending := range t.pending {
+		if pending.roID == roID && (oldest == nil || pending.sentAt.Before(oldest.sentAt)) {
+			oldest = pending
+		}
+	}
+	if oldest == nil {
+		return "", false
+	}
+	return oldest.messageID, true
+}
+
+// resolve removes a pending message and reports its outcome
+func (t *outboundTracker) resolve(messageID string, ack xml.MOSMessage, err error) {
+	t.mu.Lock()
+	pending, ok := t.pending[messageID]
+	if ok {
+		delete(t.pending, messageID)
+		pending.timer.Stop()
+	}
+	t.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	switch {
+	case err == nil:
+		t.counters.acknowledged.Add(1)
+	case errors.Is(err, ErrMessageRejected):
+		t.counters.rejected.Add(1)
+	case errors.Is(err, ErrAckTimeout):
+		t.counters.timedOut.Add(1)
+	default:
+		t.counters.dropped.Add(1)
+	}
+
+	if err != nil && !errors.Is(err, ErrConnectionClosed) {
+		t.client.trackError(err, "outbound_ack", map[string]interface{}{
+			"message_id":   pending.messageID,
+			"message_type": pending.messageType,
+			"ro_id":        pending.roID,
+			"attempts":     pending.attempts + 1,
+		})
+	}
+
+	pending.done <- PushResult{Ack: ack, Err: err}
+}
+
+// close fails every pending message when the connection closes
+func (t *outboundTracker) close() {
+	t.mu.Lock()
+	t.closed = true
+	messageIDs := make([]string, 0, len(t.pending))
+	for messageID := range t.pending {
+		messageIDs = append(messageIDs, messageID)
+	}
+	t.mu.Unlock()
+
+	for _, messageID := range messageIDs {
+		t.resolve(messageID, nil, ErrConnectionClosed)
+	}
+}
+
+// acceptedStatus reports whether an ack status accepts the message
+func acceptedStatus(status string) bool {
+	status = strings.ToUpper(strings.TrimSpace(status))
+	return status == service.ElementOK || status == "ACK" || strings.HasPrefix(status, "ACK:")
+}
+
+// messageROID returns the running order a server-initiated message refers to
+func messageROID(message xml.MOSMessage) string {
+	switch msg := message.(type) {
+	case xml.RunningOrderInfo:
+		return msg.ID
+	case xml.ROReplace:
+		return msg.ID
+	case xml.ROList:
+		return msg.ID
+	case xml.RODelete:
+		return msg.ROID
+	case xml.ROMetadataReplace:
+		return msg.ROID
+	case xml.ROStoryInsert:
+		return msg.ROID
+	case xml.ROStoryAppend:
+		return msg.ROID
+	case xml.ROStoryReplace:
+		return msg.ROID
+	case xml.ROStoryMove:
+		return msg.ROID
+	case xml.ROStorySwap:
+		return msg.ROID
+	case xml.ROStoryMoveMultiple:
+		return msg.ROID
+	case xml.ROStoryDelete:
+		return msg.ROID
+	case xml.ROItemInsert:
+		return msg.ROID
+	case xml.ROItemReplace:
+		return msg.ROID
+	case xml.ROItemMoveMultiple:
+		return msg.ROID
+	case xml.ROItemDelete:
+		return msg.ROID
+	case xml.ROElementAction:
+		return msg.ROID
+	case xml.ROReadyToAir:
+		return msg.ROID
+	case xml.ROCtrl:
+		return msg.ROID
+	case xml.ROItemCue:
+		return msg.ROID
+	case xml.ROElementStat:
+		return msg.ROID
+	case xml.ROStoryStat:
+		return msg.ROID
+	case xml.ROItemStat:
+		return msg.ROID
+	}
+	return ""
+}
+
+// OutboundStats returns the counters of server-initiated messages
+func (s *TCPServer) OutboundStats() OutboundStats {
+	return s.outbound.snapshot()
+}
+
+// logOutboundStats logs the counters of server-initiated messages whenever
+// they changed since the last report
+func (s *TCPServer) logOutboundStats(ctx context.Context) {
+	ticker := time.NewTicker(outboundStatsInterval)
+	defer ticker.Stop()
+
+	var last OutboundStats
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.shutdownCh:
+			return
+		case <-ticker.C:
+			stats := s.OutboundStats()
+			if stats == last {
+				continue
+			}
+			last = stats
+			logger.Infof("Outbound messages: %s", stats)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"airshift/openmos/internal/xml"
+)
+
+func TestPushResult(t *testing.T) {
+	tests := []struct {
+		name   string
+		status string
+		err    error
+	}{
+		{"acknowledged", "OK", nil},
+		{"rejected", "NACK", ErrMessageRejected},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, remote := newValidatingClient(t, ValidationOff)
+			go io.Copy(io.Discard, remote)
+
+			done, err := c.Push(xml.CreateRODelete("openmos.test", "", "RO1"))
+			if err != nil {
+				t.Fatalf("Push() error: %v", err)
+			}
+
+			ack := xml.CreateROAck("openmos.test", "", "RO1", tt.status)
+			ack.MOSHeader = xml.MOSHeader{MessageID: "1"}
+			if !c.outbound.acknowledge(ack) {
+				t.Fatal("acknowledge() matched no pending message")
+			}
+
+			select {
+			case result := <-done:
+				if !errors.Is(result.Err, tt.err) {
+					t.Errorf("Err = %v, want %v", result.Err, tt.err)
+				}
+				if got, ok := result.Ack.(xml.ROAck); !ok || got.ROStatus != tt.status {
+					t.Errorf("Ack = %+v, want the roAck with status %s", result.Ack, tt.status)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("no result delivered")
+			}
+		})
+	}
+}
+
+func TestRejectedRunningOrderPushEndsSubscription(t *testing.T) {
+	c, _ := newValidatingClient(t, ValidationOff)
+	c.subscribeRunningOrder("RO1")
+	update := xml.ROList{}
+
+	c.runningOrderPushFailed("RO1", update, fmt.Errorf("%w for roList 1", ErrAckTimeout))
+	if !c.isSubscribed("RO1") {
+		t.Error("an unacknowledged update ended the subscription")
+	}
+
+	c.runningOrderPushFailed("RO1", update, fmt.Errorf("%w: NACK", ErrMessageRejected))
+	if c.isSubscribed("RO1") {
+		t.Error("a rejected update did not end the subscription")
+	}
+}
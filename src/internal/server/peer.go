@@ -0,0 +1,277 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"airshift/openmos/internal/config"
+	"airshift/openmos/internal/xml"
+	"airshift/openmos/pkg/logger"
+)
+
+// Reconnect backoff of peer links
+const (
+	peerMinBackoff = time.Second
+	peerMaxBackoff = time.Minute
+)
+
+// peerLink connects OpenMOS to a downstream MOS device as its NCS. Each
+// connection is served by a ClientConnection, so the device's acks and status
+// messages go through the regular message handlers. The link reconnects with
+// exponential backoff. A mirroring link resyncs the device's running orders on
+// every connection; a redirection link only carries the changes forwarded by
+// the redirector.
+type peerLink struct {
+	server *TCPServer
+	route  config.DeviceRoute
+	mirror bool
+
+	closeChan chan struct{}
+	closeOnce sync.Once
+
+	// Connection to the device while connected
+	client   *ClientConnection
+	clientMu sync.Mutex
+
+	// Serializes running order syncs so each is created once per connection
+	syncMu sync.Mutex
+}
+
+// newPeerLinks creates a link for every configured peer device
+func newPeerLinks(server *TCPServer) []*peerLink {
+	var links []*peerLink
+	for _, route := range server.config.MOS.Peers {
+		if route.MosID == "" || route.MosID == server.config.MOS.ID {
+			logger.Warningf("Ignoring peer device with mosID %q", route.MosID)
+			continue
+		}
+		links = append(links, newPeerLink(server, route, true))
+	}
+	return links
+}
+
+// newPeerLink creates the link to a device
+func newPeerLink(server *TCPServer, route config.DeviceRoute, mirror bool) *peerLink {
+	return &peerLink{
+		server:    server,
+		route:     route,
+		mirror:    mirror,
+		closeChan: make(chan struct{}),
+	}
+}
+
+// Run keeps the link connected until the context is done or the link is closed
+func (p *peerLink) Run(ctx context.Context) {
+	address := net.JoinHostPort(p.route.Host, strconv.Itoa(p.route.Port))
+	backoff := peerMinBackoff
+
+	for {
+		conn, err := net.DialTimeout("tcp", address, p.server.config.Server.WriteTimeout)
+		if err != nil {
+			logger.Warningf("Failed to connect to peer MOS %s at %s, retrying in %s: %v", p.route.MosID, address, backoff, err)
+		} else {
+			logger.Infof("Connected to peer MOS %s at %s", p.route.MosID, address)
+			backoff = peerMinBackoff
+			p.serve(ctx, conn)
+			if p.closed(ctx) {
+				return
+			}
+			logger.Warningf("Connection to peer MOS %s lost, reconnecting in %s", p.route.MosID, backoff)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.closeChan:
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > peerMaxBackoff {
+			backoff = peerMaxBackoff
+		}
+	}
+}
+
+// serve runs a connection to the device until it closes
+func (p *peerLink) serve(ctx context.Context, conn net.Conn) {
+	client := NewClientConnection(conn, p.server, p.server.config, nil)
+	client.peer = p
+	client.ncsID = p.server.config.MOS.ID
+
+	p.clientMu.Lock()
+	p.client = client
+	p.clientMu.Unlock()
+
+	defer func() {
+		p.clientMu.Lock()
+		p.client = nil
+		p.clientMu.Unlock()
+	}()
+
+	// The link may have been closed while dialing
+	if p.closed(ctx) {
+		client.Close()
+		return
+	}
+
+	go p.heartbeatLoop(client)
+	if p.mirror {
+		go p.resync(ctx, client)
+	}
+
+	client.Start(ctx)
+}
+
+// heartbeatLoop sends heartbeats to the device while the connection is open
+func (p *peerLink) heartbeatLoop(client *ClientConnection) {
+	ticker := time.NewTicker(p.server.config.MOS.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-client.closeChan:
+			return
+		case <-ticker.C:
+			heartbeat := xml.CreateHeartbeat(p.server.config.MOS.ID, "")
+			heartbeat.MOSHeader = client.pushHeader()
+			client.heartbeatID.Store(heartbeat.MessageID)
+
+			data, err := xml.GenerateMessage(heartbeat)
+			if err == nil {
+				err = client.Write(data)
+			}
+			if err != nil {
+				logger.Warningf("Failed to send heartbeat to peer MOS %s: %v", p.route.MosID, err)
+				client.Close()
+				return
+			}
+		}
+	}
+}
+
+// resync sends the device every running order holding its items
+func (p *peerLink) resync(ctx context.Context, client *ClientConnection) {
+	ros, err := p.server.service.ListRunningOrders(ctx)
+	if err != nil {
+		logger.Errorf("Failed to list running orders to resync peer MOS %s: %v", p.route.MosID, err)
+		return
+	}
+
+	for _, ro := range ros {
+		if err := client.syncPeerRunningOrder(ctx, ro.ID); err != nil {
+			logger.Errorf("Failed to resync RO %s to peer MOS %s: %v", ro.ID, p.route.MosID, err)
+		}
+	}
+}
+
+// Client returns the connection to the device, or nil while disconnected
+func (p *peerLink) Client() *ClientConnection {
+	p.clientMu.Lock()
+	defer p.clientMu.Unlock()
+	return p.client
+}
+
+// closed reports whether the link is shutting down
+func (p *peerLink) closed(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
+		return true
+	case <-p.closeChan:
+		return true
+	default:
+		return false
+	}
+}
+
+// Close disconnects the device for good
+func (p *peerLink) Close() {
+	p.closeOnce.Do(func() {
+		close(p.closeChan)
+
+		p.clientMu.Lock()
+		defer p.clientMu.Unlock()
+		if p.client != nil {
+			p.client.Close()
+		}
+	})
+}
+
+// syncPeerRunningOrder sends a running order to a peer device: as roCreate
+// the first time on a connection and as roReplace afterwards, each story
+// carrying only the device's items. A running order without items for the
+// device is not sent, and is deleted from the device once its last item is gone.
+func (c *ClientConnection) syncPeerRunningOrder(ctx context.Context, roID string) error {
+	c.peer.syncMu.Lock()
+	defer c.peer.syncMu.Unlock()
+
+	list, err := c.buildROList(ctx, roID)
+	if err != nil {
+		return err
+	}
+
+	info := list.RunningOrderInfo
+	info.Stories = storiesFor(info.Stories, c.peer.route.MosID)
+
+	holdsItems := false
+	for _, story := range info.Stories {
+		if len(story.Items) > 0 {
+			holdsItems = true
+			break
+		}
+	}
+
+	if !holdsItems {
+		if c.isSubscribed(roID) {
+			c.unsubscribeRunningOrder(roID)
+			message := xml.CreateRODelete(c.config.MOS.ID, "", roID)
+			err = c.pushWatched(message, func(err error) {
+				c.peerSyncFailed(ctx, roID, message, err)
+			})
+		}
+		return err
+	}
+
+	var message xml.MOSMessage = info
+	if c.isSubscribed(roID) {
+		message = xml.ROReplace{RunningOrderInfo: info}
+	}
+	c.subscribeRunningOrder(roID)
+
+	logger.Infof("Sending %s for RO %s to peer MOS %s", message.GetMessageType(), roID, c.peer.route.MosID)
+
+	return c.pushWatched(message, func(err error) {
+		c.peerSyncFailed(ctx, roID, message, err)
+	})
+}
+
+// peerSyncFailed handles a running order message the device rejected or
+// never acknowledged. An unresponsive device is reconnected, which resyncs
+// all its running orders. A rejected roReplace means the device lost the
+// running order, which is sent again as roCreate; after a rejected roCreate
+// the next change retries it.
+func (c *ClientConnection) peerSyncFailed(ctx context.Context, roID string, message xml.MOSMessage, err error) {
+	mosID := c.peer.route.MosID
+
+	switch {
+	case errors.Is(err, ErrAckTimeout):
+		logger.Warningf("Peer MOS %s did not acknowledge %s for RO %s, reconnecting: %v", mosID, message.GetMessageType(), roID, err)
+		c.Close()
+	case message.GetMessageType() == "roReplace":
+		logger.Warningf("Peer MOS %s rejected roReplace for RO %s, sending it as roCreate: %v", mosID, roID, err)
+		c.unsubscribeRunningOrder(roID)
+		if err := c.syncPeerRunningOrder(ctx, roID); err != nil {
+			logger.Errorf("Failed to resync RO %s to peer MOS %s: %v", roID, mosID, err)
+		}
+	case message.GetMessageType() == "roCreate":
+		logger.Errorf("Peer MOS %s rejected roCreate for RO %s: %v", mosID, roID, err)
+		c.unsubscribeRunningOrder(roID)
+	default:
+		logger.Errorf("Peer MOS %s rejected %s for RO %s: %v", mosID, message.GetMessageType(), roID, err)
+	}
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"net"
+)
+
+// MOS port names
+const (
+	PortLower = "lower" // Object and media messages (default 10540)
+	PortUpper = "upper" // Running order messages (default 10541)
+	PortQuery = "query" // MOS 4.0 query messages (default 10542)
+)
+
+// Port sets used by routes
+var (
+	allPorts    = []string{PortLower, PortUpper, PortQuery}
+	lowerPort   = []string{PortLower}
+	upperPort   = []string{PortUpper}
+	searchPorts = []string{PortLower, PortQuery}
+)
+
+// portListener is a named MOS listener. The messages accepted on each port
+// are declared by their routes.
+type portListener struct {
+	name     string
+	listener net.Listener
+}
+
+// newPortListener opens a listener for the named MOS port
+func newPortListener(name, address string) (*portListener, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	return &portListener{
+		name:     name,
+		listener: listener,
+	}, nil
+}
+
+// Name returns the port name
+func (p *portListener) Name() string {
+	return p.name
+}
@@ -0,0 +1,30 @@
+package server
+
+// deviceType is the device type reported in listMachInfo
+const deviceType = "MOS"
+
+// supportedProfiles lists the MOS profiles fully implemented by this server.
+// Only flip a profile to true once all of its messages are handled.
+var supportedProfiles = map[int]bool{
+	0: true,  // Basic Communication
+	1: true,  // Basic Object Based Workflow
+	2: true,  // Basic Running Order / Content List Workflow
+	3: true,  // Advanced Object Based Workflow
+	4: true,  // Advanced RO/Content List Workflow
+	5: true,  // Item Control
+	6: true,  // MOS Redirection
+	7: false, // MOS RO/Content List Modification
+}
+
+// reportedProfiles returns the profiles announced in listMachInfo: the
+// supported profiles that are not disabled in the configuration
+func (s *TCPServer) reportedProfiles() map[int]bool {
+	profiles := make(map[int]bool, len(supportedProfiles))
+	for profile, supported := range supportedProfiles {
+		profiles[profile] = supported
+	}
+	for _, profile := range s.config.MOS.DisabledProfiles {
+		profiles[profile] = false
+	}
+	return profiles
+}
@@ -0,0 +1,500 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"airshift/openmos/internal/service"
+	"airshift/openmos/internal/xml"
+	"airshift/openmos/pkg/logger"
+)
+
+// redirector implements Profile 6 MOS redirection. Running order messages
+// carrying items whose mosID belongs to another MOS device are forwarded to
+// that device over a peer link, and the device's results are merged into the
+// roAck sent to the NCS. Status messages from the devices are relayed by the
+// peer links' handlers.
+type redirector struct {
+	server       *TCPServer
+	links        map[string]*peerLink
+	replyTimeout time.Duration // Longest wait for the devices before the client's roAck
+}
+
+// redirectReplyTimeout is how long the roAck of a redirected change waits
+// for the devices' results
+const redirectReplyTimeout = 2 * time.Second
+
+// redirect is a copy of an NCS message to forward to a MOS device
+type redirect struct {
+	mosID   string
+	roID    string
+	message xml.MOSMessage
+}
+
+// deviceResult is the outcome of a message redirected to a device
+type deviceResult struct {
+	mosID  string
+	done   <-chan PushResult // Nil when the message could not be sent
+	result PushResult
+}
+
+// newRedirector creates a redirector with a link to every configured device
+func newRedirector(server *TCPServer) *redirector {
+	r := &redirector{
+		server:       server,
+		links:        make(map[string]*peerLink),
+		replyTimeout: redirectReplyTimeout,
+	}
+
+	for _, route := range server.config.MOS.Redirects {
+		if route.MosID == "" || route.MosID == server.config.MOS.ID {
+			logger.Warningf("Ignoring redirection route for mosID %q", route.MosID)
+			continue
+		}
+		r.links[route.MosID] = newPeerLink(server, route, false)
+		logger.Infof("Redirecting items of %s to %s:%d", route.MosID, route.Host, route.Port)
+	}
+
+	return r
+}
+
+// peerLinks returns the links to the redirected devices in mosID order
+func (r *redirector) peerLinks() []*peerLink {
+	set := make(map[string]bool, len(r.links))
+	for mosID := range r.links {
+		set[mosID] = true
+	}
+
+	links := make([]*peerLink, 0, len(r.links))
+	for _, mosID := range sortedSet(set) {
+		links = append(links, r.links[mosID])
+	}
+	return links
+}
+
+// Route returns the copies of a running order message to forward to other MOS
+// devices. It is called before the message is applied locally so that stories
+// and items about to be deleted can still be resolved to their devices.
+func (r *redirector) Route(ctx context.Context, message xml.MOSMessage) []redirect {
+	if len(r.links) == 0 {
+		return nil
+	}
+
+	switch msg := message.(type) {
+	case xml.RunningOrderInfo:
+		return r.routeStories(msg.ID, msg.Stories, nil, func(stories []xml.StoryInfo) xml.MOSMessage {
+			msg.Stories = stories
+			return msg
+		})
+	case xml.ROReplace:
+		previous := r.runningOrderDevices(ctx, msg.ID)
+		return r.routeStories(msg.ID, msg.Stories, previous, func(stories []xml.StoryInfo) xml.MOSMessage {
+			msg.Stories = stories
+			return msg
+		})
+	case xml.ROStoryInsert:
+		return r.routeStories(msg.ROID, msg.Stories, nil, func(stories []xml.StoryInfo) xml.MOSMessage {
+			msg.Stories = stories
+			return msg
+		})
+	case xml.ROStoryAppend:
+		return r.routeStories(msg.ROID, msg.Stories, nil, func(stories []xml.StoryInfo) xml.MOSMessage {
+			msg.Stories = stories
+			return msg
+		})
+	case xml.ROStoryReplace:
+		previous := r.storyDevices(ctx, msg.StoryID)
+		return r.routeStories(msg.ROID, msg.Stories, previous, func(stories []xml.StoryInfo) xml.MOSMessage {
+			msg.Stories = stories
+			return msg
+		})
+	case xml.ROItemInsert:
+		return r.routeItems(msg.ROID, msg.Items, nil, func(items []xml.ItemInfo) xml.MOSMessage {
+			msg.Items = items
+			return msg
+		})
+	case xml.ROItemReplace:
+		previous := r.itemDevices(ctx, msg.StoryID, msg.ItemID)
+		return r.routeItems(msg.ROID, msg.Items, previous, func(items []xml.ItemInfo) xml.MOSMessage {
+			msg.Items = items
+			return msg
+		})
+	case xml.ROElementAction:
+		switch {
+		case len(msg.Elements.Stories) > 0:
+			var previous []string
+			if msg.Target.StoryID != "" {
+				previous = r.storyDevices(ctx, msg.Target.StoryID)
+			}
+			return r.routeStories(msg.ROID, msg.Elements.Stories, previous, func(stories []xml.StoryInfo) xml.MOSMessage {
+				msg.Elements.Stories = stories
+				return msg
+			})
+		case len(msg.Elements.Items) > 0:
+			var previous []string
+			if msg.Target.ItemID != "" {
+				previous = r.itemDevices(ctx, msg.Target.StoryID, msg.Target.ItemID)
+			}
+			return r.routeItems(msg.ROID, msg.Elements.Items, previous, func(items []xml.ItemInfo) xml.MOSMessage {
+				msg.Elements.Items = items
+				return msg
+			})
+		case len(msg.Elements.ItemIDs) > 0:
+			return r.routeAll(msg.ROID, msg, r.itemDevices(ctx, msg.Target.StoryID, msg.Elements.ItemIDs...))
+		default:
+			return r.routeAll(msg.ROID, msg, r.runningOrderDevices(ctx, msg.ROID))
+		}
+	case xml.ROItemMoveMultiple:
+		return r.routeAll(msg.ROID, msg, r.itemDevices(ctx, msg.StoryID, msg.ItemIDs...))
+	case xml.ROItemDelete:
+		return r.routeAll(msg.ROID, msg, r.itemDevices(ctx, msg.StoryID, msg.ItemIDs...))
+	case xml.ROCtrl:
+		if msg.ItemID != "" {
+			return r.routeAll(msg.ROID, msg, r.itemDevices(ctx, msg.StoryID, msg.ItemID))
+		}
+		if msg.StoryID != "" {
+			return r.routeAll(msg.ROID, msg, r.storyDevices(ctx, msg.StoryID))
+		}
+		return r.routeAll(msg.ROID, msg, r.runningOrderDevices(ctx, msg.ROID))
+	case xml.ROItemCue:
+		if _, ok := r.links[msg.MosID]; ok {
+			return r.routeAll(msg.ROID, msg, []string{msg.MosID})
+		}
+	case xml.ROStoryMove:
+		return r.routeAll(msg.ROID, msg, r.runningOrderDevices(ctx, msg.ROID))
+	case xml.ROStorySwap:
+		return r.routeAll(msg.ROID, msg, r.runningOrderDevices(ctx, msg.ROID))
+	case xml.ROStoryMoveMultiple:
+		return r.routeAll(msg.ROID, msg, r.runningOrderDevices(ctx, msg.ROID))
+	case xml.ROStoryDelete:
+		return r.routeAll(msg.ROID, msg, r.storyDevices(ctx, msg.StoryIDs...))
+	case xml.ROMetadataReplace:
+		return r.routeAll(msg.ROID, msg, r.runningOrderDevices(ctx, msg.ROID))
+	case xml.ROReadyToAir:
+		return r.routeAll(msg.ROID, msg, r.runningOrderDevices(ctx, msg.ROID))
+	case xml.RODelete:
+		return r.routeAll(msg.ROID, msg, r.runningOrderDevices(ctx, msg.ROID))
+	}
+
+	return nil
+}
+
+// Send forwards the routed messages to their devices on behalf of the client
+func (r *redirector) Send(client *ClientConnection, redirects []redirect) []deviceResult {
+	results := make([]deviceResult, 0, len(redirects))
+	for _, rd := range redirects {
+		link, ok := r.links[rd.mosID]
+		if !ok {
+			continue
+		}
+
+		logger.Infof("Redirecting %s for RO %s from client %s to %s", rd.message.GetMessageType(), rd.roID, client.ID(), rd.mosID)
+
+		result := deviceResult{mosID: rd.mosID}
+		if device := link.Client(); device == nil {
+			result.result.Err = fmt.Errorf("MOS %s is unreachable", rd.mosID)
+		} else {
+			result.done, result.result.Err = device.Push(rd.message)
+		}
+		if result.result.Err != nil {
+			logger.Errorf("Failed to redirect %s to %s: %v", rd.message.GetMessageType(), rd.mosID, result.result.Err)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// reply sends the client the local roAck merged with the devices' results.
+// It waits for the devices at most the reply timeout, so that the client's
+// own ack timeout does not expire first; a device that has not answered by
+// then is reported as failed. Without a local roAck the device failures are
+// only logged.
+func (r *redirector) reply(client *ClientConnection, ack *xml.ROAck, results []deviceResult) {
+	deadline := time.NewTimer(r.replyTimeout)
+	defer deadline.Stop()
+	expired := false
+
+	for i := range results {
+		if results[i].done != nil && !expired {
+			select {
+			case results[i].result = <-results[i].done:
+			case <-deadline.C:
+				expired = true
+			}
+		}
+		if results[i].done != nil && expired {
+			// Take an answer that arrived meanwhile without waiting again
+			select {
+			case results[i].result = <-results[i].done:
+			default:
+				results[i].result.Err = fmt.Errorf("no answer within %s", r.replyTimeout)
+			}
+		}
+		if err := results[i].result.Err; err != nil {
+			logger.Warningf("MOS %s did not accept a change redirected from client %s: %v", results[i].mosID, client.ID(), err)
+		}
+	}
+
+	if ack == nil {
+		return
+	}
+	if err := client.writeROAck(mergeROAck(*ack, results)); err != nil {
+		logger.Errorf("Failed to send running order ack to client %s: %v", client.ID(), err)
+	}
+}
+
+// mergeROAck adds the elements acknowledged by the devices to a roAck. The
+// status stays the local one when every device accepted the change, and is
+// NACK otherwise; the reasons are logged by reply and reported in the
+// devices' element statuses.
+func mergeROAck(ack xml.ROAck, results []deviceResult) xml.ROAck {
+	failed := false
+	for _, result := range results {
+		if deviceAck, ok := result.result.Ack.(xml.ROAck); ok {
+			for _, element := range deviceAck.Elements {
+				element.Status = service.TruncateStatus(element.Status)
+				ack.Elements = append(ack.Elements, element)
+			}
+		}
+		if result.result.Err != nil {
+			failed = true
+		}
+	}
+
+	if failed && acceptedStatus(ack.ROStatus) {
+		ack.ROStatus = service.ElementNACK
+	}
+	return ack
+}
+
+// routeStories forwards stories to every device owning one of their items, or
+// listed in previous. Each device receives all stories with only its own items.
+func (r *redirector) routeStories(roID string, stories []xml.StoryInfo, previous []string, build func([]xml.StoryInfo) xml.MOSMessage) []redirect {
+	var items []xml.ItemInfo
+	for _, story := range stories {
+		items = append(items, story.Items...)
+	}
+
+	var redirects []redirect
+	for _, mosID := range r.devices(items, previous) {
+		redirects = append(redirects, redirect{
+			mosID:   mosID,
+			roID:    roID,
+			message: build(storiesFor(stories, mosID)),
+		})
+	}
+	return redirects
+}
+
+// routeItems forwards items to every device owning one of them, or listed in
+// previous. Each device receives only its own items.
+func (r *redirector) routeItems(roID string, items []xml.ItemInfo, previous []string, build func([]xml.ItemInfo) xml.MOSMessage) []redirect {
+	var redirects []redirect
+	for _, mosID := range r.devices(items, previous) {
+		redirects = append(redirects, redirect{
+			mosID:   mosID,
+			roID:    roID,
+			message: build(itemsFor(items, mosID)),
+		})
+	}
+	return redirects
+}
+
+// routeAll forwards the message unchanged to each of the devices
+func (r *redirector) routeAll(roID string, message xml.MOSMessage, devices []string) []redirect {
+	var redirects []redirect
+	for _, mosID := range devices {
+		redirects = append(redirects, redirect{
+			mosID:   mosID,
+			roID:    roID,
+			message: message,
+		})
+	}
+	return redirects
+}
+
+// devices returns the redirected devices owning any of the items, together
+// with the previous devices, in a stable order
+func (r *redirector) devices(items []xml.ItemInfo, previous []string) []string {
+	set := make(map[string]bool)
+	for _, mosID := range previous {
+		set[mosID] = true
+	}
+	for _, item := range items {
+		if _, ok := r.links[item.MosID]; ok {
+			set[item.MosID] = true
+		}
+	}
+	return sortedSet(set)
+}
+
+// runningOrderDevices returns the redirected devices owning items in a running order
+func (r *redirector) runningOrderDevices(ctx context.Context, roID string) []string {
+	_, stories, err := r.server.service.GetRunningOrderWithStories(ctx, roID)
+	if err != nil {
+		return nil
+	}
+
+	storyIDs := make([]string, 0, len(stories))
+	for _, story := range stories {
+		storyIDs = append(storyIDs, story.ID)
+	}
+	return r.storyDevices(ctx, storyIDs...)
+}
+
+// storyDevices returns the redirected devices owning items in the stories
+func (r *redirector) storyDevices(ctx context.Context, storyIDs ...string) []string {
+	set := make(map[string]bool)
+	for _, storyID := range storyIDs {
+		items, err := r.server.service.GetItemsForStory(ctx, storyID)
+		if err != nil {
+			continue
+		}
+		for _, item := range items {
+			if _, ok := r.links[item.MosID]; ok {
+				set[item.MosID] = true
+			}
+		}
+	}
+	return sortedSet(set)
+}
+
+// itemDevices returns the redirected devices owning the items of a story
+func (r *redirector) itemDevices(ctx context.Context, storyID string, itemIDs ...string) []string {
+	items, err := r.server.service.GetItemsForStory(ctx, storyID)
+	if err != nil {
+		return nil
+	}
+
+	wanted := make(map[string]bool)
+	for _, itemID := range itemIDs {
+		wanted[itemID] = true
+	}
+
+	set := make(map[string]bool)
+	for _, item := range items {
+		if !wanted[item.ItemID] {
+			continue
+		}
+		if _, ok := r.links[item.MosID]; ok {
+			set[item.MosID] = true
+		}
+	}
+	return sortedSet(set)
+}
+
+// storiesFor returns the stories with only the items owned by a device
+func storiesFor(stories []xml.StoryInfo, mosID string) []xml.StoryInfo {
+	result := make([]xml.StoryInfo, 0, len(stories))
+	for _, story := range stories {
+		story.Items = itemsFor(story.Items, mosID)
+		result = append(result, story)
+	}
+	return result
+}
+
+// itemsFor returns the items owned by a device
+func itemsFor(items []xml.ItemInfo, mosID string) []xml.ItemInfo {
+	var result []xml.ItemInfo
+	for _, item := range items {
+		if item.MosID == mosID {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// sortedSet returns the members of a set in sorted order
+func sortedSet(set map[string]bool) []string {
+	members := make([]string, 0, len(set))
+	for member := range set {
+		members = append(members, member)
+	}
+	sort.Strings(members)
+	return members
+}
+
+// ackHold keeps the roAck of a change being redirected until the devices'
+// results are merged into it
+type ackHold struct {
+	active bool
+	ack    *xml.ROAck
+	mu     sync.Mutex
+}
+
+// holdROAck starts holding the roAck sent by the handler
+func (c *ClientConnection) holdROAck() {
+	c.redirectAck.mu.Lock()
+	defer c.redirectAck.mu.Unlock()
+	c.redirectAck.active = true
+	c.redirectAck.ack = nil
+}
+
+// releaseROAck stops holding and returns the held roAck, if any
+func (c *ClientConnection) releaseROAck() *xml.ROAck {
+	c.redirectAck.mu.Lock()
+	defer c.redirectAck.mu.Unlock()
+	c.redirectAck.active = false
+	ack := c.redirectAck.ack
+	c.redirectAck.ack = nil
+	return ack
+}
+
+// heldROAck keeps a roAck instead of sending it while a change is redirected
+func (c *ClientConnection) heldROAck(ack xml.ROAck) bool {
+	c.redirectAck.mu.Lock()
+	defer c.redirectAck.mu.Unlock()
+	if !c.redirectAck.active {
+		return false
+	}
+	c.redirectAck.ack = &ack
+	return true
+}
+
+// relay sends a message from a redirected device to the client
+func (c *ClientConnection) relay(message xml.MOSMessage) error {
+	data, err := xml.GenerateMessage(message)
+	if err != nil {
+		return fmt.Errorf("failed to generate relayed %s: %w", message.GetMessageType(), err)
+	}
+
+	return c.Write(data)
+}
+
+// relayStatus relays a status message from a redirected device to the upper
+// port clients of the NCS owning the running order
+func (s *TCPServer) relayStatus(ctx context.Context, mosID, roID string, message xml.MOSMessage) {
+	ro, err := s.service.GetRunningOrder(ctx, roID)
+	if err != nil {
+		logger.Warningf("Dropping %s from %s for unknown RO %s", message.GetMessageType(), mosID, roID)
+		return
+	}
+
+	s.clientsMu.RLock()
+	clients := make([]*ClientConnection, 0, len(s.clients))
+	for _, client := range s.clients {
+		clients = append(clients, client)
+	}
+	s.clientsMu.RUnlock()
+
+	for _, client := range clients {
+		if client.port != nil && client.port.Name() != PortUpper {
+			continue
+		}
+		if ro.NcsID != "" && ro.NcsID != client.NcsID() {
+			continue
+		}
+
+		relayed := xml.WithHeader(message, xml.MOSHeader{
+			MosID:     mosID,
+			NcsID:     client.NcsID(),
+			MessageID: client.nextMessageID(),
+		})
+		if err := client.relay(relayed); err != nil {
+			logger.Errorf("Failed to relay %s from %s to client %s: %v", message.GetMessageType(), mosID, client.ID(), err)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"airshift/openmos/internal/xml"
+)
+
+func TestMergeROAck(t *testing.T) {
+	local := xml.CreateROAckResults("openmos.test", "1", "RO1", "OK", []xml.ROAckElement{
+		xml.CreateROAckElement("STORY1", "ITEM1", "OBJ1", "A", "OK"),
+	})
+	deviceAck := xml.CreateROAckResults("gfx.test", "", "RO1", "OK", []xml.ROAckElement{
+		xml.CreateROAckElement("STORY1", "ITEM2", "GFX1", "B", "OK"),
+	})
+
+	tests := []struct {
+		name     string
+		local    xml.ROAck
+		results  []deviceResult
+		status   string
+		elements int
+	}{
+		{
+			name:     "every device accepted",
+			local:    local,
+			results:  []deviceResult{{mosID: "gfx.test", result: PushResult{Ack: deviceAck}}},
+			status:   "OK",
+			elements: 2,
+		},
+		{
+			name:  "device rejected",
+			local: local,
+			results: []deviceResult{{mosID: "gfx.test", result: PushResult{
+				Ack: deviceAck,
+				Err: fmt.Errorf("%w: NACK", ErrMessageRejected),
+			}}},
+			status:   "NACK",
+			elements: 2,
+		},
+		{
+			name:     "device unreachable",
+			local:    local,
+			results:  []deviceResult{{mosID: "gfx.test", result: PushResult{Err: fmt.Errorf("MOS gfx.test is unreachable")}}},
+			status:   "NACK",
+			elements: 1,
+		},
+		{
+			name:     "local failure is kept",
+			local:    xml.CreateROAck("openmos.test", "1", "RO1", "Failed to store running order"),
+			results:  []deviceResult{{mosID: "gfx.test", result: PushResult{Err: fmt.Errorf("MOS gfx.test is unreachable")}}},
+			status:   "Failed to store running order",
+			elements: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged := mergeROAck(tt.local, tt.results)
+			if merged.ROStatus != tt.status {
+				t.Errorf("roStatus = %q, want %q", merged.ROStatus, tt.status)
+			}
+			if len(merged.Elements) != tt.elements {
+				t.Errorf("%d elements, want %d", len(merged.Elements), tt.elements)
+			}
+		})
+	}
+}
+
+func TestRedirectedROAckIsHeld(t *testing.T) {
+	c, _ := newValidatingClient(t, ValidationOff)
+
+	c.holdROAck()
+	// The pipe has no reader, so an ack that is not held would block and fail
+	if err := c.sendROAck(xml.MOSHeader{MessageID: "7"}, "", "RO1", "OK"); err != nil {
+		t.Fatalf("sendROAck() error: %v", err)
+	}
+
+	ack := c.releaseROAck()
+	if ack == nil || ack.ROID != "RO1" || ack.MessageID != "7" {
+		t.Fatalf("releaseROAck() = %+v, want the roAck for RO1 replying to message 7", ack)
+	}
+	if c.releaseROAck() != nil {
+		t.Error("releaseROAck() returned the ack twice")
+	}
+}
+
+func TestRedirectReplyDoesNotWaitPastTimeout(t *testing.T) {
+	c, remote := newValidatingClient(t, ValidationOff)
+	r := &redirector{replyTimeout: 50 * time.Millisecond}
+
+	ack := xml.CreateROAck("openmos.test", "", "RO1", "OK")
+	ack.MOSHeader = xml.MOSHeader{MessageID: "7"}
+	silent := make(chan PushResult, 1)
+
+	go r.reply(c, &ack, []deviceResult{{mosID: "gfx.test", done: silent}})
+
+	replies := make(chan string, 1)
+	go func() {
+		buffer := make([]byte, 4096)
+		n, _ := remote.Read(buffer)
+		replies <- string(buffer[:n])
+	}()
+
+	select {
+	case reply := <-replies:
+		if !strings.Contains(reply, "<roStatus>NACK</roStatus>") {
+			t.Errorf("reply %q does not report the silent device", reply)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no roAck sent after the reply timeout")
+	}
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"airshift/openmos/internal/xml"
+)
+
+// HandlerFunc handles a message received on a connection
+type HandlerFunc func(ctx context.Context, c *ClientConnection, message xml.MOSMessage) error
+
+// Middleware wraps the handler of a route with behavior shared by messages,
+// such as authentication, tracing, metrics or deduplication. It may answer
+// the message itself instead of calling next.
+type Middleware func(route Route, next HandlerFunc) HandlerFunc
+
+// Route binds a message type to its handler. The message type is the root
+// element of the message inside the <mos> envelope; its decoder is
+// registered with xml.RegisterMessage.
+type Route struct {
+	MessageType string
+	Profile     int      // MOS profile defining the message
+	Ports       []string // MOS ports accepting the message; none limits it to peer connections
+	Handler     HandlerFunc
+}
+
+// acceptedOn reports whether the route accepts messages on the named port
+func (r Route) acceptedOn(port string) bool {
+	for _, name := range r.Ports {
+		if name == port {
+			return true
+		}
+	}
+	return false
+}
+
+// Registry holds the route of every message type handled by the server and
+// the middleware wrapped around them. Embedders add vendor-specific messages
+// with Handle and cross-cutting behavior with Use.
+type Registry struct {
+	routes     map[string]Route
+	middleware []Middleware
+	mu         sync.RWMutex
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		routes: make(map[string]Route),
+	}
+}
+
+// Handle registers the route of a message type, replacing any previous one
+func (r *Registry) Handle(route Route) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.routes[route.MessageType] = route
+}
+
+// Use appends middleware to the chain. Middleware runs in the order it was
+// added, the first added being the outermost.
+func (r *Registry) Use(middleware ...Middleware) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.middleware = append(r.middleware, middleware...)
+}
+
+// Lookup returns the route registered for a message type
+func (r *Registry) Lookup(messageType string) (Route, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	route, ok := r.routes[messageType]
+	return route, ok
+}
+
+// Dispatch runs a message received on a connection through the middleware
+// chain to its handler
+func (r *Registry) Dispatch(ctx context.Context, c *ClientConnection, message xml.MOSMessage) error {
+	r.mu.RLock()
+	route, ok := r.routes[message.GetMessageType()]
+	middleware := r.middleware
+	r.mu.RUnlock()
+
+	if !ok || route.Handler == nil {
+		return fmt.Errorf("no handler for message type %s", message.GetMessageType())
+	}
+
+	handler := route.Handler
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](route, handler)
+	}
+
+	return handler(ctx, c, message)
+}
+
+// Registry extensions added to the registry of every server
+var (
+	extensions   []func(*Registry)
+	extensionsMu sync.Mutex
+)
+
+// Extend registers a function adding routes and middleware to the registry of
+// every server created afterwards, after the built-in ones. Embedders call it
+// from init to handle vendor-specific messages.
+func Extend(extension func(*Registry)) {
+	extensionsMu.Lock()
+	defer extensionsMu.Unlock()
+	extensions = append(extensions, extension)
+}
+
+// applyExtensions runs the registered extensions on a registry
+func applyExtensions(registry *Registry) {
+	extensionsMu.Lock()
+	defer extensionsMu.Unlock()
+	for _, extension := range extensions {
+		extension(registry)
+	}
+}
+
+// handle adapts a ClientConnection method taking a concrete message type
+func handle[T xml.MOSMessage](method func(*ClientConnection, context.Context, T) error) HandlerFunc {
+	return func(ctx context.Context, c *ClientConnection, message xml.MOSMessage) error {
+		msg, ok := message.(T)
+		if !ok {
+			return fmt.Errorf("unexpected message type: %T", message)
+		}
+		return method(c, ctx, msg)
+	}
+}
+
+// Registry returns the message handler registry of the server. Routes and
+// middleware should be added before Start.
+func (s *TCPServer) Registry() *Registry {
+	return s.registry
+}
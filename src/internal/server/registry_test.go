@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"airshift/openmos/internal/config"
+	"airshift/openmos/internal/xml"
+)
+
+func TestExtendAddsRoutesToNewServers(t *testing.T) {
+	Extend(func(registry *Registry) {
+		registry.Handle(Route{
+			MessageType: "vndTallyState",
+			Ports:       lowerPort,
+			Handler: func(ctx context.Context, c *ClientConnection, message xml.MOSMessage) error {
+				return nil
+			},
+		})
+	})
+
+	// No listening ports, so the server only builds its registry
+	server, err := NewTCPServer(&config.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("NewTCPServer() error: %v", err)
+	}
+
+	if _, ok := server.Registry().Lookup("vndTallyState"); !ok {
+		t.Error("extension route missing from the server registry")
+	}
+	if _, ok := server.Registry().Lookup("roCreate"); !ok {
+		t.Error("built-in route missing from the server registry")
+	}
+}
+
+func TestQueryPortAcceptsObjectSearches(t *testing.T) {
+	server, err := NewTCPServer(&config.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("NewTCPServer() error: %v", err)
+	}
+
+	tests := []struct {
+		messageType string
+		accepted    bool
+	}{
+		{"mosReqObjList", true},
+		{"mosReqSearchableSchema", true},
+		{"heartbeat", true},
+		{"mosReqObj", false},
+		{"roCreate", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.messageType, func(t *testing.T) {
+			route, ok := server.Registry().Lookup(tt.messageType)
+			if !ok {
+				t.Fatalf("no route for %s", tt.messageType)
+			}
+
+			c, remote := newValidatingClient(t, ValidationOff)
+			c.port = &portListener{name: PortQuery}
+			go io.Copy(io.Discard, remote)
+
+			handled := false
+			handler := portMiddleware(route, func(ctx context.Context, c *ClientConnection, message xml.MOSMessage) error {
+				handled = true
+				return nil
+			})
+			if err := handler(context.Background(), c, xml.MOSReqObjList{}); err != nil {
+				t.Fatalf("handler error: %v", err)
+			}
+			if handled != tt.accepted {
+				t.Errorf("handled on the query port = %v, want %v", handled, tt.accepted)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+
+	"airshift/openmos/internal/xml"
+)
+
+// defaultRoutes lists the messages handled by OpenMOS. Basic communication
+// and mosAck, the general acknowledgment, are accepted on every port; object
+// messages on the lower port and running order messages on the upper port.
+// Object searches are also accepted on the MOS 4.0 query port. Device status is only accepted from the peer devices OpenMOS connects to.
+func defaultRoutes() []Route {
+	return []Route{
+		// Profile 0 - Basic Communication
+		{MessageType: "heartbeat", Profile: 0, Ports: allPorts, Handler: handle((*ClientConnection).handleHeartbeat)},
+		{MessageType: "reqMachInfo", Profile: 0, Ports: allPorts, Handler: handle((*ClientConnection).handleReqMachInfo)},
+		{MessageType: "listMachInfo", Profile: 0, Ports: allPorts, Handler: handle((*ClientConnection).handleListMachInfo)},
+		{MessageType: "mosAck", Profile: 0, Ports: allPorts, Handler: handle((*ClientConnection).handleMOSAck)},
+
+		// Profile 1 - Basic Object Based Workflow
+		{MessageType: "mosObj", Profile: 1, Ports: lowerPort, Handler: handle((*ClientConnection).handleMOSObj)},
+		{MessageType: "mosReqObj", Profile: 1, Ports: lowerPort, Handler: handle((*ClientConnection).handleMOSReqObj)},
+		{MessageType: "mosReqAll", Profile: 1, Ports: lowerPort, Handler: handle((*ClientConnection).handleMOSReqAll)},
+		{MessageType: "mosListAll", Profile: 1, Ports: lowerPort, Handler: handle((*ClientConnection).handleMOSListAll)},
+
+		// Profile 2 - Basic Running Order Workflow
+		{MessageType: "roCreate", Profile: 2, Ports: upperPort, Handler: handle((*ClientConnection).handleRunningOrderInfo)},
+		{MessageType: "roReplace", Profile: 2, Ports: upperPort, Handler: handle(func(c *ClientConnection, ctx context.Context, msg xml.ROReplace) error {
+			return c.handleROReplace(ctx, msg.RunningOrderInfo)
+		})},
+		{MessageType: "roList", Profile: 2, Ports: upperPort, Handler: handle(func(c *ClientConnection, ctx context.Context, msg xml.ROList) error {
+			return c.handleRunningOrderInfo(ctx, msg.RunningOrderInfo)
+		})},
+		{MessageType: "roMetadataReplace", Profile: 2, Ports: upperPort, Handler: handle((*ClientConnection).handleROMetadataReplace)},
+		{MessageType: "roDelete", Profile: 2, Ports: upperPort, Handler: handle((*ClientConnection).handleRODelete)},
+		{MessageType: "roReq", Profile: 2, Ports: upperPort, Handler: handle((*ClientConnection).handleReqRunningOrder)},
+		{MessageType: "roAck", Profile: 2, Ports: upperPort, Handler: handle((*ClientConnection).handleROAck)},
+		{MessageType: "roStoryInsert", Profile: 2, Ports: upperPort, Handler: handle((*ClientConnection).handleROStoryInsert)},
+		{MessageType: "roStoryAppend", Profile: 2, Ports: upperPort, Handler: handle((*ClientConnection).handleROStoryAppend)},
+		{MessageType: "roStoryReplace", Profile: 2, Ports: upperPort, Handler: handle((*ClientConnection).handleROStoryReplace)},
+		{MessageType: "roStoryMove", Profile: 2, Ports: upperPort, Handler: handle((*ClientConnection).handleROStoryMove)},
+		{MessageType: "roStorySwap", Profile: 2, Ports: upperPort, Handler: handle((*ClientConnection).handleROStorySwap)},
+		{MessageType: "roStoryDelete", Profile: 2, Ports: upperPort, Handler: handle((*ClientConnection).handleROStoryDelete)},
+		{MessageType: "roStoryMoveMultiple", Profile: 2, Ports: upperPort, Handler: handle((*ClientConnection).handleROStoryMoveMultiple)},
+		{MessageType: "roItemInsert", Profile: 2, Ports: upperPort, Handler: handle((*ClientConnection).handleROItemInsert)},
+		{MessageType: "roItemReplace", Profile: 2, Ports: upperPort, Handler: handle((*ClientConnection).handleROItemReplace)},
+		{MessageType: "roItemMoveMultiple", Profile: 2, Ports: upperPort, Handler: handle((*ClientConnection).handleROItemMoveMultiple)},
+		{MessageType: "roItemDelete", Profile: 2, Ports: upperPort, Handler: handle((*ClientConnection).handleROItemDelete)},
+		{MessageType: "roElementAction", Profile: 2, Ports: upperPort, Handler: handle((*ClientConnection).handleROElementAction)},
+		{MessageType: "roReadyToAir", Profile: 2, Ports: upperPort, Handler: handle((*ClientConnection).handleROReadyToAir)},
+		{MessageType: "roElementStat", Profile: 2, Handler: handle(func(c *ClientConnection, ctx context.Context, msg xml.ROElementStat) error {
+			return c.handleDeviceStatus(ctx, msg.ROID, msg)
+		})},
+		{MessageType: "roStoryStat", Profile: 2, Handler: handle(func(c *ClientConnection, ctx context.Context, msg xml.ROStoryStat) error {
+			return c.handleDeviceStatus(ctx, msg.ROID, msg)
+		})},
+		{MessageType: "roItemStat", Profile: 2, Handler: handle(func(c *ClientConnection, ctx context.Context, msg xml.ROItemStat) error {
+			return c.handleDeviceStatus(ctx, msg.ROID, msg)
+		})},
+
+		// Profile 3 - Advanced Object Based Workflow
+		{MessageType: "mosObjCreate", Profile: 3, Ports: lowerPort, Handler: handle((*ClientConnection).handleMOSObjCreate)},
+		{MessageType: "mosReqObjAction", Profile: 3, Ports: lowerPort, Handler: handle((*ClientConnection).handleMOSReqObjAction)},
+		{MessageType: "mosReqSearchableSchema", Profile: 3, Ports: searchPorts, Handler: handle((*ClientConnection).handleMOSReqSearchableSchema)},
+		{MessageType: "mosReqObjList", Profile: 3, Ports: searchPorts, Handler: handle((*ClientConnection).handleMOSReqObjList)},
+		{MessageType: "mosItemReplace", Profile: 3, Ports: upperPort, Handler: handle((*ClientConnection).handleMOSItemReplace)},
+
+		// Profile 4 - Advanced RO/Content List Workflow
+		{MessageType: "roReqAll", Profile: 4, Ports: upperPort, Handler: handle((*ClientConnection).handleReqRunningOrderList)},
+		{MessageType: "roStorySend", Profile: 4, Ports: upperPort, Handler: handle((*ClientConnection).handleROStorySend)},
+
+		// Profile 5 - Item Control
+		{MessageType: "roCtrl", Profile: 5, Ports: upperPort, Handler: handle((*ClientConnection).handleROCtrl)},
+		{MessageType: "roItemCue", Profile: 5, Ports: upperPort, Handler: handle((*ClientConnection).handleROItemCue)},
+
+		// Profile 8 - ActiveX plug-ins
+		{MessageType: "ncsReqStoryAction", Profile: 8, Ports: upperPort, Handler: handle((*ClientConnection).handleNCSReqStoryAction)},
+	}
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	"airshift/openmos/internal/service"
+	"airshift/openmos/pkg/logger"
+)
+
+// schemaServer serves the searchable schema announced in
+// mosListSearchableSchema, which NCSs fetch over HTTP to build their queries
+type schemaServer struct {
+	listener net.Listener
+	server   *http.Server
+}
+
+// newSchemaServer listens on the schema port. It returns nil when the port is 0.
+func newSchemaServer(address string, port int) (*schemaServer, error) {
+	if port == 0 {
+		return nil, nil
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create schema listener on %s: %w", address, err)
+	}
+
+	document := service.SearchableSchemaDocument()
+	mux := http.NewServeMux()
+	mux.HandleFunc(service.SearchableSchemaPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		w.Write(document)
+	})
+
+	return &schemaServer{
+		listener: listener,
+		server:   &http.Server{Handler: mux},
+	}, nil
+}
+
+// Serve answers schema requests until the server is closed
+func (s *schemaServer) Serve() {
+	logger.Infof("Serving searchable schema on http://%s%s", s.listener.Addr(), service.SearchableSchemaPath)
+	if err := s.server.Serve(s.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Errorf("Searchable schema server failed: %v", err)
+	}
+}
+
+// Close stops the schema server
+func (s *schemaServer) Close(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+// searchSchemaURL returns the searchable schema URL reported to a client: the
+// configured URL, or the schema port on the address the client connected to
+func (c *ClientConnection) searchSchemaURL() string {
+	if c.config.MOS.SearchSchema != "" {
+		return c.config.MOS.SearchSchema
+	}
+	if c.server.schema == nil {
+		return service.SearchableSchema
+	}
+
+	host, _, err := net.SplitHostPort(c.conn.LocalAddr().String())
+	if err != nil {
+		return service.SearchableSchema
+	}
+	address := net.JoinHostPort(host, strconv.Itoa(c.config.Server.SchemaPort))
+	return "http://" + address + service.SearchableSchemaPath
+}
@@ -10,12 +10,13 @@ import (
 	"airshift/openmos/internal/config"
 	"airshift/openmos/internal/events"
 	"airshift/openmos/internal/service"
+	"airshift/openmos/internal/xml"
 	"airshift/openmos/pkg/logger"
 )
 
 // TCPServer represents the TCP socket server
 type TCPServer struct {
-	listener   net.Listener
+	listeners  []*portListener
 	clients    map[string]*ClientConnection
 	clientsMu  sync.RWMutex
 	service    *service.MOSService
@@ -23,79 +24,167 @@ type TCPServer struct {
 	eventBus   *events.EventBus
 	wg         sync.WaitGroup
 	shutdownCh chan struct{}
+	startedAt  time.Time
+	encoding   xml.Encoding
+	redirector *redirector
+	peers      []*peerLink
+	outbound   outboundCounters
+	validator  *validator
+	registry   *Registry
+	schema     *schemaServer
 }
 
-// NewTCPServer creates a new TCP server instance
+// NewTCPServer creates a new TCP server instance listening on the MOS lower,
+// upper and query ports
 func NewTCPServer(cfg *config.Config, mosService *service.MOSService, eventBus *events.EventBus) (*TCPServer, error) {
-	address := cfg.GetServerAddress()
-	listener, err := net.Listen("tcp", address)
+	ports := []struct {
+		name string
+		port int
+	}{
+		{PortLower, cfg.Server.Port},
+		{PortUpper, cfg.Server.UpperPort},
+		{PortQuery, cfg.Server.QueryPort},
+	}
+
+	encoding, err := xml.ParseEncoding(cfg.MOS.Encoding)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MOS encoding: %w", err)
+	}
+
+	validator, err := newValidator(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create listener on %s: %w", address, err)
+		return nil, err
 	}
 
 	server := &TCPServer{
-		listener:   listener,
 		clients:    make(map[string]*ClientConnection),
 		service:    mosService,
 		config:     cfg,
 		eventBus:   eventBus,
 		shutdownCh: make(chan struct{}),
+		startedAt:  time.Now(),
+		encoding:   encoding,
+		validator:  validator,
+	}
+	server.redirector = newRedirector(server)
+	server.peers = append(newPeerLinks(server), server.redirector.peerLinks()...)
+
+	server.registry = NewRegistry()
+	server.registry.Use(server.defaultMiddleware()...)
+	for _, route := range defaultRoutes() {
+		server.registry.Handle(route)
+	}
+	applyExtensions(server.registry)
+
+	for _, p := range ports {
+		if p.port == 0 {
+			continue
+		}
+
+		address := cfg.GetPortAddress(p.port)
+		listener, err := newPortListener(p.name, address)
+		if err != nil {
+			server.closeListeners()
+			return nil, fmt.Errorf("failed to create %s port listener on %s: %w", p.name, address, err)
+		}
+		server.listeners = append(server.listeners, listener)
+	}
+
+	server.schema, err = newSchemaServer(cfg.GetPortAddress(cfg.Server.SchemaPort), cfg.Server.SchemaPort)
+	if err != nil {
+		server.closeListeners()
+		return nil, err
 	}
 
 	return server, nil
 }
 
-// Start begins accepting connections
+// Start begins accepting connections on all ports
 func (s *TCPServer) Start(ctx context.Context) error {
 	defer s.wg.Done()
 	s.wg.Add(1)
 
-	address := s.listener.Addr().String()
-	logger.Infof("Server listening on %s", address)
+	for _, pl := range s.listeners {
+		logger.Infof("Server listening on %s (%s port)", pl.listener.Addr().String(), pl.name)
+
+		// Accept connections in a loop
+		go s.acceptLoop(ctx, pl)
+	}
+
+	if s.schema != nil {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.schema.Serve()
+		}()
+	}
 
-	// Accept connections in a loop
+	// Report the outcome of server-initiated messages
+	s.wg.Add(1)
 	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-s.shutdownCh:
-				return
-			default:
-				// Set accept timeout so we can check for shutdown
-				tcpListener, ok := s.listener.(*net.TCPListener)
-				if ok {
-					tcpListener.SetDeadline(time.Now().Add(time.Second))
-				}
+		defer s.wg.Done()
+		s.logOutboundStats(ctx)
+	}()
+
+	// Connect to the downstream and redirected MOS devices
+	for _, peer := range s.peers {
+		s.wg.Add(1)
+		go func(peer *peerLink) {
+			defer s.wg.Done()
+			peer.Run(ctx)
+		}(peer)
+	}
 
-				conn, err := s.listener.Accept()
-				if err != nil {
-					if opErr, ok := err.(*net.OpError); ok && opErr.Timeout() {
-						// This is just a timeout from our deadline, continue
-						continue
-					}
-					logger.Errorf("Error accepting connection: %v", err)
+	<-ctx.Done()
+	return s.Shutdown(context.Background())
+}
+
+// acceptLoop accepts connections on a single port until shutdown
+func (s *TCPServer) acceptLoop(ctx context.Context, pl *portListener) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.shutdownCh:
+			return
+		default:
+			// Set accept timeout so we can check for shutdown
+			tcpListener, ok := pl.listener.(*net.TCPListener)
+			if ok {
+				tcpListener.SetDeadline(time.Now().Add(time.Second))
+			}
+
+			conn, err := pl.listener.Accept()
+			if err != nil {
+				if opErr, ok := err.(*net.OpError); ok && opErr.Timeout() {
+					// This is just a timeout from our deadline, continue
 					continue
 				}
+				logger.Errorf("Error accepting connection on %s port: %v", pl.name, err)
+				continue
+			}
 
-				// Create new client connection
-				client := NewClientConnection(conn, s, s.config)
+			// Create new client connection
+			client := NewClientConnection(conn, s, s.config, pl)
 
-				// Register client
-				s.registerClient(client)
+			// Register client
+			s.registerClient(client)
 
-				// Handle client in a goroutine
-				s.wg.Add(1)
-				go func() {
-					defer s.wg.Done()
-					client.Start(ctx)
-				}()
-			}
+			// Handle client in a goroutine
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				client.Start(ctx)
+			}()
 		}
-	}()
+	}
+}
 
-	<-ctx.Done()
-	return s.Shutdown(context.Background())
+// closeListeners closes all port listeners
+func (s *TCPServer) closeListeners() {
+	for _, pl := range s.listeners {
+		pl.listener.Close()
+	}
 }
 
 // Shutdown gracefully shuts down the server
@@ -105,9 +194,18 @@ func (s *TCPServer) Shutdown(ctx context.Context) error {
 	// Signal all goroutines to stop
 	close(s.shutdownCh)
 
-	// Close listener
-	if s.listener != nil {
-		s.listener.Close()
+	// Close listeners
+	s.closeListeners()
+
+	if s.schema != nil {
+		if err := s.schema.Close(ctx); err != nil {
+			logger.Errorf("Failed to stop searchable schema server: %v", err)
+		}
+	}
+
+	// Disconnect from the downstream and redirected MOS devices
+	for _, peer := range s.peers {
+		peer.Close()
 	}
 
 	// Close all client connections
@@ -133,6 +231,7 @@ func (s *TCPServer) Shutdown(ctx context.Context) error {
 	case <-shutdownCtx.Done():
 		return fmt.Errorf("server shutdown timed out")
 	case <-done:
+		logger.Infof("Outbound messages: %s", s.OutboundStats())
 		logger.Info("Server shutdown complete")
 		return nil
 	}
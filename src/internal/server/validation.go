@@ -0,0 +1,106 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"airshift/openmos/internal/config"
+	"airshift/openmos/internal/xml"
+	"airshift/openmos/pkg/logger"
+)
+
+// Schema validation modes
+const (
+	ValidationOff    = "off"
+	ValidationWarn   = "warn"
+	ValidationStrict = "strict"
+)
+
+// schemaPaths are searched for the MOS XSD when none is configured
+var schemaPaths = []string{
+	filepath.Join("res", "mosv4.xsd"),       // Repository root
+	filepath.Join("..", "res", "mosv4.xsd"), // Source directory
+}
+
+// validator checks the messages exchanged with clients against the MOS schema
+type validator struct {
+	schema *xml.Schema
+	strict bool
+}
+
+// newValidator loads the schema for the configured validation mode. It
+// returns nil when validation is off.
+func newValidator(cfg *config.Config) (*validator, error) {
+	mode := strings.ToLower(strings.TrimSpace(cfg.MOS.Validation))
+	switch mode {
+	case "", ValidationOff:
+		return nil, nil
+	case ValidationWarn, ValidationStrict:
+	default:
+		return nil, fmt.Errorf("unsupported validation mode: %s", cfg.MOS.Validation)
+	}
+
+	paths := schemaPaths
+	if cfg.MOS.Schema != "" {
+		paths = []string{cfg.MOS.Schema}
+	}
+
+	for _, path := range paths {
+		schema, err := xml.LoadSchema(path)
+		if errors.Is(err, os.ErrNotExist) && cfg.MOS.Schema == "" {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load MOS schema: %w", err)
+		}
+
+		logger.Infof("Validating MOS messages against %s (%s mode)", path, mode)
+		return &validator{schema: schema, strict: mode == ValidationStrict}, nil
+	}
+
+	return nil, fmt.Errorf("MOS schema not found in %s", strings.Join(paths, ", "))
+}
+
+// acceptInvalid decides what happens to an incoming message breaking the
+// schema. In warn mode the violations are logged and the message is handled.
+// In strict mode it is answered with a NACK naming the violations and
+// dropped; acks are always handled, as answering them could loop.
+func (c *ClientConnection) acceptInvalid(message xml.MOSMessage, invalid *xml.ValidationError) bool {
+	switch message.(type) {
+	case xml.MOSAck, xml.ROAck:
+		logger.Warningf("[Client %s] %v", c.id, invalid)
+		return true
+	}
+
+	if !c.server.validator.strict {
+		logger.Warningf("[Client %s] %v", c.id, invalid)
+		return true
+	}
+
+	c.recordHeader(invalid.Header)
+	c.trackError(invalid, "validate", map[string]interface{}{
+		"message_type": invalid.MessageType,
+	})
+	if err := c.sendErrorAck(invalid.Header, "", "NACK", invalid.Error()); err != nil {
+		c.trackError(err, "send_nack", nil)
+	}
+	return false
+}
+
+// checkOutgoing logs the schema violations of a message about to be sent.
+// Outgoing messages are never held back, whatever the mode.
+func (c *ClientConnection) checkOutgoing(data []byte) {
+	if c.server.validator == nil {
+		return
+	}
+
+	var invalid *xml.ValidationError
+	if err := c.server.validator.schema.ValidateMessage(data); errors.As(err, &invalid) {
+		c.trackError(invalid, "validate_outgoing", map[string]interface{}{
+			"message_type": invalid.MessageType,
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"airshift/openmos/internal/config"
+	"airshift/openmos/internal/service"
+	"airshift/openmos/internal/xml"
+)
+
+// invalidROCreate is an roCreate missing its required roID
+const invalidROCreate = `<mos><mosID>openmos.test</mosID><ncsID>ncs.test</ncsID><messageID>7</messageID>` +
+	`<roCreate><roSlug>Evening News</roSlug></roCreate></mos>`
+
+// newValidatingClient returns a client connection validating in the given
+// mode, and the peer end of its connection
+func newValidatingClient(t *testing.T, mode string) (*ClientConnection, net.Conn) {
+	t.Helper()
+
+	cfg := &config.Config{}
+	cfg.Server.WriteTimeout = time.Second
+	cfg.MOS.ID = "openmos.test"
+	cfg.MOS.HeartbeatInterval = time.Minute
+	cfg.MOS.ClientTimeout = time.Minute
+	cfg.MOS.AckTimeout = time.Second
+	cfg.MOS.Validation = mode
+	cfg.MOS.Schema = filepath.Join("..", "..", "..", "res", "mosv4.xsd")
+
+	validator, err := newValidator(cfg)
+	if err != nil {
+		t.Fatalf("newValidator() error: %v", err)
+	}
+
+	local, remote := net.Pipe()
+	t.Cleanup(func() {
+		local.Close()
+		remote.Close()
+	})
+
+	server := &TCPServer{config: cfg, encoding: xml.EncodingUTF8, validator: validator}
+	return NewClientConnection(local, server, cfg, nil), remote
+}
+
+// parseInvalid parses invalidROCreate with the client's parser
+func parseInvalid(t *testing.T, c *ClientConnection) (xml.MOSMessage, *xml.ValidationError) {
+	t.Helper()
+
+	c.parser.AppendData([]byte(invalidROCreate))
+	message, _, err := c.parser.Parse()
+
+	var invalid *xml.ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("Parse() error = %v, want a *xml.ValidationError", err)
+	}
+	return message, invalid
+}
+
+func TestStrictValidationRejectsInvalidMessages(t *testing.T) {
+	c, remote := newValidatingClient(t, ValidationStrict)
+	message, invalid := parseInvalid(t, c)
+
+	replies := make(chan string, 1)
+	go func() {
+		remote.SetReadDeadline(time.Now().Add(2 * time.Second))
+		data, _ := io.ReadAll(io.LimitReader(remote, 4096))
+		replies <- string(data)
+	}()
+
+	if c.acceptInvalid(message, invalid) {
+		t.Fatal("strict mode accepted an invalid message")
+	}
+	c.conn.Close()
+
+	reply := <-replies
+	for _, want := range []string{"<mosAck ", "<status>NACK</status>", "<messageID>7</messageID>", "roCreate"} {
+		if !strings.Contains(reply, want) {
+			t.Errorf("reply %q does not contain %q", reply, want)
+		}
+	}
+}
+
+func TestWarnValidationAcceptsInvalidMessages(t *testing.T) {
+	c, _ := newValidatingClient(t, ValidationWarn)
+	message, invalid := parseInvalid(t, c)
+
+	if !c.acceptInvalid(message, invalid) {
+		t.Fatal("warn mode rejected an invalid message")
+	}
+}
+
+func TestStrictValidationAcceptsInvalidAcks(t *testing.T) {
+	c, _ := newValidatingClient(t, ValidationStrict)
+
+	ack := xml.MOSAck{Status: "ACK"}
+	if !c.acceptInvalid(ack, &xml.ValidationError{MessageType: "mosAck"}) {
+		t.Fatal("strict mode rejected an ack, which must never be answered")
+	}
+}
+
+func TestValidationOff(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.MOS.Validation = ValidationOff
+
+	validator, err := newValidator(cfg)
+	if err != nil || validator != nil {
+		t.Errorf("newValidator() = %v, %v; want no validator", validator, err)
+	}
+}
+
+func TestPartlyFailedROAckMatchesSchema(t *testing.T) {
+	c, _ := newValidatingClient(t, ValidationStrict)
+
+	reason := errors.New("item duration " + strings.Repeat("9", 150) + " is not a number")
+	results := []service.ElementStatus{
+		{StoryID: "STORY1", Status: service.ElementOK},
+		{StoryID: "STORY2", ItemID: "ITEM1", Status: service.FailureStatus(reason), Err: reason},
+		{StoryID: "STORY3", ItemID: "ITEM2", Status: service.FailureStatus(reason), Err: reason},
+	}
+
+	// Hold the ack to inspect it instead of writing it
+	c.holdROAck()
+	if err := c.sendROAckResults(xml.MOSHeader{MessageID: "7"}, "", "RO1", results, nil); err != nil {
+		t.Fatalf("sendROAckResults() error: %v", err)
+	}
+	ack := c.releaseROAck()
+
+	if ack.ROStatus != service.ElementNACK {
+		t.Errorf("roStatus = %q, want %q", ack.ROStatus, service.ElementNACK)
+	}
+
+	data, err := xml.GenerateMessage(*ack)
+	if err != nil {
+		t.Fatalf("GenerateMessage() error: %v", err)
+	}
+	if err := c.server.validator.schema.ValidateMessage(data); err != nil {
+		t.Errorf("%v\n%s", err, data)
+	}
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"time"
@@ -10,6 +11,7 @@ import (
 	"airshift/openmos/internal/model"
 	"airshift/openmos/internal/repository"
 	"airshift/openmos/internal/xml"
+	"airshift/openmos/pkg/utils"
 )
 
 // MOSService provides business logic for MOS operations
@@ -19,6 +21,7 @@ type MOSService struct {
 	itemRepo         repository.ItemRepository
 	objectRepo       repository.ObjectRepository
 	eventBus         *events.EventBus
+	controls         controlSchedule
 }
 
 // NewMOSService creates a new MOS service
@@ -38,11 +41,21 @@ func NewMOSService(
 	}
 }
 
+// Close stops the scheduled control commands that have not run yet
+func (s *MOSService) Close() {
+	s.controls.stop()
+}
+
 // ListRunningOrders returns all running orders
 func (s *MOSService) ListRunningOrders(ctx context.Context) ([]*model.RunningOrder, error) {
 	return s.runningOrderRepo.List(ctx)
 }
 
+// GetRunningOrder retrieves a running order without its stories
+func (s *MOSService) GetRunningOrder(ctx context.Context, id string) (*model.RunningOrder, error) {
+	return s.runningOrderRepo.Get(ctx, id)
+}
+
 // GetRunningOrderWithStories retrieves a running order with all its stories
 func (s *MOSService) GetRunningOrderWithStories(ctx context.Context, id string) (*model.RunningOrder, []*model.Story, error) {
 	// Get the running order
@@ -65,105 +78,216 @@ func (s *MOSService) GetItemsForStory(ctx context.Context, storyID string) ([]*m
 	return s.itemRepo.ListByStory(ctx, storyID)
 }
 
-// ProcessRunningOrderInfo processes a running order creation/update message
-func (s *MOSService) ProcessRunningOrderInfo(ctx context.Context, roInfo xml.RunningOrderInfo) error {
-	// Check if running order exists
-	existingRO, err := s.runningOrderRepo.Get(ctx, roInfo.ID)
+// ProcessRunningOrderInfo processes a running order creation/replacement message.
+// The stored story and item tree is reconciled against the message: missing
+// stories and items are deleted, new ones created and existing ones updated.
+// Stories that fail validation are rejected individually and reported in the
+// returned element results; the error is only set when the whole message fails.
+func (s *MOSService) ProcessRunningOrderInfo(ctx context.Context, roInfo xml.RunningOrderInfo) ([]ElementStatus, error) {
+	return s.storeRunningOrder(ctx, roInfo, true)
+}
 
-	// Parse duration if provided
-	var duration int
-	if roInfo.Duration != "" {
-		duration, err = strconv.Atoi(roInfo.Duration)
-		if err != nil {
-			duration = 0
-		}
-	}
+// ReplaceRunningOrder processes an roReplace message. Unlike roCreate, it
+// fails for running orders that do not exist.
+func (s *MOSService) ReplaceRunningOrder(ctx context.Context, roInfo xml.RunningOrderInfo) ([]ElementStatus, error) {
+	return s.storeRunningOrder(ctx, roInfo, false)
+}
 
-	// Create or update running order
-	if err != nil { // Running order doesn't exist
-		// Create new running order
-		ro := &model.RunningOrder{
+// storeRunningOrder creates or replaces a running order. Missing running
+// orders are only created when create is set.
+func (s *MOSService) storeRunningOrder(ctx context.Context, roInfo xml.RunningOrderInfo, create bool) ([]ElementStatus, error) {
+	// Check if running order exists
+	ro, err := s.runningOrderRepo.Get(ctx, roInfo.ID)
+	isNew := errors.Is(err, repository.ErrNotFound)
+	if err != nil && (!isNew || !create) {
+		return nil, err
+	}
+	if isNew {
+		ro = &model.RunningOrder{
 			ID:        roInfo.ID,
-			Slug:      roInfo.Slug,
 			Status:    model.StatusPending,
-			Duration:  duration,
-			Channel:   roInfo.Channel,
 			Version:   1,
 			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
 		}
+	} else if err := checkAirLock(ctx, ro, OperationReplace); err != nil {
+		return nil, err
+	} else {
+		// Commands scheduled for the old version no longer apply
+		s.controls.cancelRunningOrder(roInfo.ID)
+	}
+
+	ro.Slug = roInfo.Slug
+	ro.Channel = roInfo.Channel
+	if ncsID := roInfo.GetHeader().NcsID; ncsID != "" {
+		ro.NcsID = ncsID
+	}
+	ro.UpdatedAt = time.Now()
+
+	// Reconcile stories and their items
+	stories, results, err := s.syncStories(ctx, roInfo.ID, roInfo.Stories)
+	if err != nil {
+		return nil, err
+	}
+
+	summarizeRunningOrder(ro, stories)
+
+	// Fall back to the declared duration for running orders without stories
+	if len(stories) == 0 && roInfo.Duration != "" {
+		if duration, err := strconv.Atoi(roInfo.Duration); err == nil {
+			ro.Duration = duration
+		}
+	}
 
+	// Create or update running order
+	if isNew {
 		_, err = s.runningOrderRepo.Create(ctx, ro)
 		if err != nil {
-			return fmt.Errorf("failed to create running order: %w", err)
+			return nil, fmt.Errorf("failed to create running order: %w", err)
 		}
 	} else {
-		// Update existing running order
-		existingRO.Slug = roInfo.Slug
-		existingRO.Channel = roInfo.Channel
-		existingRO.Duration = duration
-		existingRO.UpdatedAt = time.Now()
-
-		err = s.runningOrderRepo.Update(ctx, existingRO)
+		err = s.runningOrderRepo.Update(ctx, ro)
 		if err != nil {
-			return fmt.Errorf("failed to update running order: %w", err)
+			return nil, fmt.Errorf("failed to update running order: %w", err)
 		}
 	}
 
-	// Process stories (simplified - full implementation would handle deletions, etc.)
-	for i, storyInfo := range roInfo.Stories {
-		// Create or update each story
-		story := &model.Story{
-			ID:             storyInfo.ID,
-			RunningOrderID: roInfo.ID,
-			Slug:           storyInfo.Slug,
-			Number:         storyInfo.Number,
-			Status:         model.StatusPending,
-			Order:          i + 1,
-			CreatedAt:      time.Now(),
-			UpdatedAt:      time.Now(),
+	// Publish event after successful update
+	if s.eventBus != nil {
+		s.eventBus.Publish(events.Event{
+			Type:    events.RunningOrderUpdated,
+			Payload: roInfo.ID,
+			Source:  "mos_service",
+		})
+	}
+
+	return results, nil
+}
+
+// DeleteRunningOrder deletes a running order together with all its stories and items
+func (s *MOSService) DeleteRunningOrder(ctx context.Context, roID string) error {
+	// Check if running order exists
+	ro, err := s.runningOrderRepo.Get(ctx, roID)
+	if err != nil {
+		return err
+	}
+
+	err = checkAirLock(ctx, ro, OperationDelete)
+	if err != nil {
+		return err
+	}
+
+	// Delete stories and their items
+	stories, err := s.storyRepo.ListByRunningOrder(ctx, roID)
+	if err != nil {
+		return fmt.Errorf("failed to list stories: %w", err)
+	}
+
+	for _, story := range stories {
+		err = s.deleteStoryTree(ctx, story.ID)
+		if err != nil {
+			return err
 		}
+	}
+
+	// Delete the running order itself
+	err = s.runningOrderRepo.Delete(ctx, roID)
+	if err != nil {
+		return fmt.Errorf("failed to delete running order: %w", err)
+	}
+	s.controls.cancelRunningOrder(roID)
+
+	// Publish event after successful deletion
+	if s.eventBus != nil {
+		s.eventBus.Publish(events.Event{
+			Type:    events.RunningOrderDeleted,
+			Payload: roID,
+			Source:  "mos_service",
+		})
+	}
 
-		// Parse duration if provided
-		if storyInfo.Duration != "" {
-			if storyDuration, err := strconv.Atoi(storyInfo.Duration); err == nil {
-				story.Duration = storyDuration
-			}
+	return nil
+}
+
+// deleteStoryTree deletes a story and all its items
+func (s *MOSService) deleteStoryTree(ctx context.Context, storyID string) error {
+	items, err := s.itemRepo.ListByStory(ctx, storyID)
+	if err != nil {
+		return fmt.Errorf("failed to list items for story %s: %w", storyID, err)
+	}
+
+	for _, item := range items {
+		err = s.itemRepo.Delete(ctx, item.ID)
+		if err != nil {
+			return fmt.Errorf("failed to delete item %s: %w", item.ID, err)
 		}
+	}
+
+	err = s.storyRepo.Delete(ctx, storyID)
+	if err != nil {
+		return fmt.Errorf("failed to delete story %s: %w", storyID, err)
+	}
 
-		// Create or update the story
-		existingStory, err := s.storyRepo.Get(ctx, storyInfo.ID)
+	return nil
+}
+
+// ReplaceRunningOrderMetadata updates the header fields of a running order
+// without touching its stories. Fields absent from the message are kept. It is
+// not air-locked, since no story or item is removed.
+func (s *MOSService) ReplaceRunningOrderMetadata(ctx context.Context, meta xml.ROMetadataReplace) error {
+	ro, err := s.runningOrderRepo.Get(ctx, meta.ROID)
+	if err != nil {
+		return err
+	}
+
+	if meta.Slug != "" {
+		ro.Slug = meta.Slug
+	}
+	if meta.Channel != "" {
+		ro.Channel = meta.Channel
+	}
+	if meta.EdStart != "" {
+		airTime, err := xml.ParseTime(meta.EdStart)
+		if err != nil {
+			return fmt.Errorf("invalid roEdStart %q: %w", meta.EdStart, err)
+		}
+		ro.AirTime = &airTime
+	}
+	if meta.EdDur != "" {
+		edDuration, err := utils.ParseDuration(meta.EdDur)
 		if err != nil {
-			// Story doesn't exist, create it
-			_, err = s.storyRepo.Create(ctx, story)
-			if err != nil {
-				return fmt.Errorf("failed to create story: %w", err)
-			}
-		} else {
-			// Story exists, update it
-			existingStory.Slug = storyInfo.Slug
-			existingStory.Number = storyInfo.Number
-			existingStory.Order = i + 1
-			existingStory.UpdatedAt = time.Now()
-
-			if storyInfo.Duration != "" {
-				if storyDuration, err := strconv.Atoi(storyInfo.Duration); err == nil {
-					existingStory.Duration = storyDuration
-				}
-			}
-
-			err = s.storyRepo.Update(ctx, existingStory)
-			if err != nil {
-				return fmt.Errorf("failed to update story: %w", err)
-			}
+			return fmt.Errorf("invalid roEdDur %q: %w", meta.EdDur, err)
 		}
+		ro.EdDuration = edDuration
+	}
+	if meta.Trigger != "" {
+		ro.Trigger = meta.Trigger
+	}
+	if meta.MacroIn != "" {
+		ro.MacroIn = meta.MacroIn
+	}
+	if meta.MacroOut != "" {
+		ro.MacroOut = meta.MacroOut
 	}
 
-	// Publish event after successful update
+	// Merge external metadata, keyed by schema
+	if len(meta.ExternalMeta) > 0 && ro.Metadata == nil {
+		ro.Metadata = make(map[string]string)
+	}
+	for _, external := range meta.ExternalMeta {
+		ro.Metadata[external.MosSchema] = external.MosPayload.Content
+	}
+
+	// Update bumps the running order version
+	err = s.runningOrderRepo.Update(ctx, ro)
+	if err != nil {
+		return fmt.Errorf("failed to update running order: %w", err)
+	}
+
+	// Publish a header-only change event
 	if s.eventBus != nil {
 		s.eventBus.Publish(events.Event{
-			Type:    events.RunningOrderUpdated,
-			Payload: roInfo.ID,
+			Type:    events.RunningOrderMetadataUpdated,
+			Payload: meta.ROID,
 			Source:  "mos_service",
 		})
 	}
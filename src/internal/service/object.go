@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"airshift/openmos/internal/events"
+	"airshift/openmos/internal/model"
+	"airshift/openmos/internal/xml"
+	"airshift/openmos/pkg/utils"
+)
+
+// ObjectDeleted is the mosObj status announcing that an object was removed
+const ObjectDeleted = "DELETED"
+
+// Operations of mosReqObjAction
+const (
+	ObjectActionNew    = "NEW"
+	ObjectActionUpdate = "UPDATE"
+	ObjectActionDelete = "DELETE"
+)
+
+// objectIDPrefix prefixes the IDs of objects created by an NCS
+const objectIDPrefix = "OBJ"
+
+// GetObject retrieves a MOS object
+func (s *MOSService) GetObject(ctx context.Context, objID string) (*model.MOSObject, error) {
+	return s.objectRepo.Get(ctx, objID)
+}
+
+// ListObjects returns the whole object catalog
+func (s *MOSService) ListObjects(ctx context.Context) ([]*model.MOSObject, error) {
+	return s.objectRepo.List(ctx)
+}
+
+// StoreObject creates or updates an object from its mosObj description. An
+// object announced with status DELETED is removed from the catalog.
+func (s *MOSService) StoreObject(ctx context.Context, info xml.MOSObj) (*model.MOSObject, error) {
+	if info.ObjID == "" {
+		return nil, fmt.Errorf("missing objID")
+	}
+
+	obj, err := s.objectRepo.Get(ctx, info.ObjID)
+	isNew := err != nil
+
+	if info.Status == ObjectDeleted {
+		if isNew {
+			return nil, err
+		}
+
+		err = s.objectRepo.Delete(ctx, info.ObjID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete object %s: %w", info.ObjID, err)
+		}
+
+		obj.Status = model.StatusType(ObjectDeleted)
+		s.publishObjectChange(obj)
+		return obj, nil
+	}
+
+	if isNew {
+		obj = &model.MOSObject{
+			ID:       info.ObjID,
+			ObjectID: info.ObjID,
+		}
+	}
+
+	applyObjInfo(obj, info)
+
+	if isNew {
+		_, err = s.objectRepo.Create(ctx, obj)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create object: %w", err)
+		}
+	} else {
+		err = s.objectRepo.Update(ctx, obj)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update object: %w", err)
+		}
+	}
+
+	s.publishObjectChange(obj)
+
+	return obj, nil
+}
+
+// CreateObject creates a placeholder object requested by an NCS. The object
+// gets a generated ID and starts out PENDING and not ready to air.
+func (s *MOSService) CreateObject(ctx context.Context, fields xml.ObjectFields) (*model.MOSObject, error) {
+	if fields.ObjSlug == "" {
+		return nil, fmt.Errorf("missing objSlug")
+	}
+
+	id, err := utils.GenerateID(objectIDPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate object ID: %w", err)
+	}
+
+	obj := &model.MOSObject{
+		ID:       id,
+		ObjectID: id,
+		Status:   model.StatusPending,
+		Air:      "NOT READY",
+		Revision: 1,
+	}
+	applyObjectFields(obj, fields)
+
+	_, err = s.objectRepo.Create(ctx, obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create object: %w", err)
+	}
+
+	s.publishObjectChange(obj)
+
+	return obj, nil
+}
+
+// ApplyObjectAction creates, updates or deletes an object on behalf of an NCS
+func (s *MOSService) ApplyObjectAction(ctx context.Context, action xml.MOSReqObjAction) (*model.MOSObject, error) {
+	operation := strings.ToUpper(action.Operation)
+	if operation == ObjectActionNew {
+		return s.CreateObject(ctx, action.ObjectFields)
+	}
+
+	if action.ObjID == "" {
+		return nil, fmt.Errorf("missing objID for %s", operation)
+	}
+
+	obj, err := s.objectRepo.Get(ctx, action.ObjID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch operation {
+	case ObjectActionUpdate:
+		applyObjectFields(obj, action.ObjectFields)
+		obj.Revision++
+
+		err = s.objectRepo.Update(ctx, obj)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update object: %w", err)
+		}
+
+	case ObjectActionDelete:
+		err = s.objectRepo.Delete(ctx, obj.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete object %s: %w", obj.ID, err)
+		}
+		obj.Status = model.StatusType(ObjectDeleted)
+
+	default:
+		return nil, fmt.Errorf("unsupported object action operation %q", action.Operation)
+	}
+
+	s.publishObjectChange(obj)
+
+	return obj, nil
+}
+
+// publishObjectChange publishes a created, updated or deleted object
+func (s *MOSService) publishObjectChange(obj *model.MOSObject) {
+	if s.eventBus == nil {
+		return
+	}
+
+	s.eventBus.Publish(events.Event{
+		Type:    events.ObjectChanged,
+		Payload: obj,
+		Source:  "mos_service",
+	})
+}
+
+// applyObjInfo copies the fields of an incoming mosObj onto a stored object
+func applyObjInfo(obj *model.MOSObject, info xml.MOSObj) {
+	obj.Slug = info.ObjSlug
+	obj.MosAbstract = info.MosAbstract
+	obj.Group = info.ObjGroup
+	obj.ObjectType = info.ObjType
+	obj.Air = info.ObjAir
+	obj.Description = info.Description
+	obj.CreatedBy = info.CreatedBy
+	obj.ChangedBy = info.ChangedBy
+	obj.Status = model.StatusType(info.Status)
+
+	if timeBase, err := strconv.Atoi(info.ObjTB); err == nil {
+		obj.TimeBase = timeBase
+	}
+	if frames, err := strconv.Atoi(info.ObjDur); err == nil {
+		obj.Frames = frames
+	}
+	obj.Duration = 0
+	if obj.TimeBase > 0 {
+		obj.Duration = obj.Frames / obj.TimeBase
+	}
+
+	// Keep revisions increasing when the sender does not track them
+	if revision, err := strconv.Atoi(info.ObjRev); err == nil {
+		obj.Revision = revision
+	} else {
+		obj.Revision++
+	}
+
+	obj.Paths = nil
+	if info.ObjPaths != nil {
+		for _, path := range info.ObjPaths.Paths {
+			obj.Paths = append(obj.Paths, model.ObjectPath{Type: model.PathMedia, TechDescription: path.TechDescription, URL: path.URL})
+		}
+		for _, path := range info.ObjPaths.ProxyPaths {
+			obj.Paths = append(obj.Paths, model.ObjectPath{Type: model.PathProxy, TechDescription: path.TechDescription, URL: path.URL})
+		}
+		for _, path := range info.ObjPaths.MetadataPaths {
+			obj.Paths = append(obj.Paths, model.ObjectPath{Type: model.PathMetadata, TechDescription: path.TechDescription, URL: path.URL})
+		}
+	}
+
+	// Merge external metadata, keyed by schema
+	if len(info.ExternalMeta) > 0 && obj.Metadata == nil {
+		obj.Metadata = make(map[string]string)
+	}
+	for _, external := range info.ExternalMeta {
+		obj.Metadata[external.MosSchema] = external.MosPayload.Content
+	}
+}
+
+// applyObjectFields copies the fields sent by an NCS onto an object. Fields
+// absent from the message are kept.
+func applyObjectFields(obj *model.MOSObject, fields xml.ObjectFields) {
+	if fields.ObjSlug != "" {
+		obj.Slug = fields.ObjSlug
+	}
+	if fields.MosAbstract != "" {
+		obj.MosAbstract = fields.MosAbstract
+	}
+	if fields.ObjGroup != "" {
+		obj.Group = fields.ObjGroup
+	}
+	if fields.ObjType != "" {
+		obj.ObjectType = fields.ObjType
+	}
+	if fields.CreatedBy != "" {
+		obj.CreatedBy = fields.CreatedBy
+	}
+	if fields.ChangedBy != "" {
+		obj.ChangedBy = fields.ChangedBy
+	}
+	if fields.Description != "" {
+		obj.Description = fields.Description
+	}
+
+	if timeBase, err := strconv.Atoi(fields.ObjTB); err == nil {
+		obj.TimeBase = timeBase
+	}
+	if frames, err := strconv.Atoi(fields.ObjDur); err == nil {
+		obj.Frames = frames
+	}
+	if obj.TimeBase > 0 {
+		obj.Duration = obj.Frames / obj.TimeBase
+	}
+
+	// Merge external metadata, keyed by schema
+	if len(fields.ExternalMeta) > 0 && obj.Metadata == nil {
+		obj.Metadata = make(map[string]string)
+	}
+	for _, external := range fields.ExternalMeta {
+		obj.Metadata[external.MosSchema] = external.MosPayload.Content
+	}
+}
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"airshift/openmos/internal/model"
+	"airshift/openmos/internal/repository"
+	"airshift/openmos/internal/xml"
+)
+
+// SearchableSchema is the namespace of the schema describing the object
+// fields that can be used in mosReqObjList
+const SearchableSchema = "urn:openmos:schema:mosObj-search"
+
+// SearchableSchemaPath is the HTTP path the searchable schema is served at
+const SearchableSchemaPath = "/schema/mosObj-search.xsd"
+
+// defaultSearchPageSize is the page size when listReturnEnd is not given
+const defaultSearchPageSize = 50
+
+// searchFields maps the searchable mosObj fields to stored object fields
+var searchFields = map[string]string{
+	"objID":       "_id",
+	"objSlug":     "slug",
+	"mosAbstract": "mosAbstract",
+	"objGroup":    "group",
+	"objType":     "objectType",
+	"objAir":      "air",
+	"status":      "status",
+	"createdBy":   "createdBy",
+	"changedBy":   "changedBy",
+	"description": "description",
+	"objTB":       "timeBase",
+	"objRev":      "revision",
+	"objDur":      "frames",
+}
+
+// numericSearchFields lists the stored fields compared as numbers
+var numericSearchFields = map[string]bool{
+	"timeBase": true,
+	"revision": true,
+	"frames":   true,
+}
+
+var (
+	// searchPathPattern splits an XPath such as /objSlug[.='Fire'] into field and predicate
+	searchPathPattern = regexp.MustCompile(`^/?([\w/]+)\s*(?:\[(.*)\])?$`)
+	// searchComparePattern matches a comparison such as . >= 25
+	searchComparePattern = regexp.MustCompile(`^\.?\s*(!=|<=|>=|=|<|>)\s*(.+)$`)
+	// searchContainsPattern matches contains(., 'text')
+	searchContainsPattern = regexp.MustCompile(`^contains\(\s*\.\s*,\s*(.+)\)$`)
+)
+
+// ObjectSearchResult is one page of a catalog search
+type ObjectSearchResult struct {
+	Objects []*model.MOSObject
+	// Start and End are the 1-based positions of the returned objects
+	Start int
+	End   int
+	Total int
+	// Status describes an empty or failed search
+	Status string
+}
+
+// SearchObjects runs a mosReqObjList catalog search. The general search is
+// matched against the text fields; each searchGroup is a set of ANDed field
+// clauses and the groups are ORed.
+func (s *MOSService) SearchObjects(ctx context.Context, req xml.MOSReqObjList) (*ObjectSearchResult, error) {
+	if req.MosSchema != "" && req.MosSchema != SearchableSchema && !strings.HasSuffix(req.MosSchema, SearchableSchemaPath) {
+		return nil, fmt.Errorf("unknown search schema %q", req.MosSchema)
+	}
+
+	start, end, err := searchPage(req.ListReturnStart, req.ListReturnEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	query := repository.ObjectQuery{
+		Text:  strings.TrimSpace(req.GeneralSearch),
+		Skip:  start - 1,
+		Limit: end - start + 1,
+	}
+
+	var sorts []searchSort
+	for _, group := range req.SearchGroups {
+		var conditions []repository.ObjectCondition
+		for _, field := range group.Fields {
+			name, parsed, err := parseSearchField(field.XPath)
+			if err != nil {
+				return nil, err
+			}
+			conditions = append(conditions, parsed...)
+
+			if field.SortByOrder != "" {
+				order, err := strconv.Atoi(field.SortByOrder)
+				if err != nil {
+					return nil, fmt.Errorf("invalid sortByOrder %q", field.SortByOrder)
+				}
+				sorts = append(sorts, searchSort{order: order, key: repository.ObjectSort{
+					Field:      name,
+					Descending: strings.EqualFold(field.SortType, "DESCENDING"),
+				}})
+			}
+		}
+		if len(conditions) > 0 {
+			query.Groups = append(query.Groups, conditions)
+		}
+	}
+	query.Sort = orderSorts(sorts)
+
+	objects, total, err := s.objectRepo.Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ObjectSearchResult{
+		Objects: objects,
+		Total:   total,
+	}
+	if len(objects) == 0 {
+		result.Status = "No objects found"
+		return result, nil
+	}
+
+	result.Start = start
+	result.End = start + len(objects) - 1
+	return result, nil
+}
+
+// searchSort is a sort key with its requested priority
+type searchSort struct {
+	order int
+	key   repository.ObjectSort
+}
+
+// orderSorts returns the sort keys by ascending sortByOrder
+func orderSorts(sorts []searchSort) []repository.ObjectSort {
+	sort.SliceStable(sorts, func(i, j int) bool {
+		return sorts[i].order < sorts[j].order
+	})
+
+	var ordered []repository.ObjectSort
+	for _, sorted := range sorts {
+		ordered = append(ordered, sorted.key)
+	}
+	return ordered
+}
+
+// searchPage returns the 1-based page bounds of a search request
+func searchPage(startValue, endValue string) (int, int, error) {
+	start := 1
+	if startValue = strings.TrimSpace(startValue); startValue != "" {
+		value, err := strconv.Atoi(startValue)
+		if err != nil || value < 1 {
+			return 0, 0, fmt.Errorf("invalid listReturnStart %q", startValue)
+		}
+		start = value
+	}
+
+	end := start + defaultSearchPageSize - 1
+	if endValue = strings.TrimSpace(endValue); endValue != "" {
+		value, err := strconv.Atoi(endValue)
+		if err != nil || value < start {
+			return 0, 0, fmt.Errorf("invalid listReturnEnd %q", endValue)
+		}
+		end = value
+	}
+
+	return start, end, nil
+}
+
+// parseSearchField parses a searchField XPath such as /objSlug[.='Fire'],
+// /objDur[. > 250 AND . < 500] or /description[contains(., 'storm')] into the
+// stored field name and its conditions. A field without a predicate only sorts.
+func parseSearchField(path string) (string, []repository.ObjectCondition, error) {
+	match := searchPathPattern.FindStringSubmatch(strings.TrimSpace(path))
+	if match == nil {
+		return "", nil, fmt.Errorf("invalid search XPath %q", path)
+	}
+
+	name := strings.TrimPrefix(match[1], "mosObj/")
+	field, ok := searchFields[name]
+	if !ok {
+		return "", nil, fmt.Errorf("field %q is not searchable", name)
+	}
+
+	predicate := strings.TrimSpace(match[2])
+	if predicate == "" {
+		return field, nil, nil
+	}
+
+	var conditions []repository.ObjectCondition
+	for _, clause := range strings.Split(predicate, " AND ") {
+		clause = strings.TrimSpace(clause)
+
+		if contains := searchContainsPattern.FindStringSubmatch(clause); contains != nil {
+			conditions = append(conditions, repository.ObjectCondition{
+				Field:    field,
+				Operator: repository.OpContains,
+				Value:    unquote(contains[1]),
+			})
+			continue
+		}
+
+		compare := searchComparePattern.FindStringSubmatch(clause)
+		if compare == nil {
+			return "", nil, fmt.Errorf("invalid search predicate %q", clause)
+		}
+
+		var value interface{} = unquote(compare[2])
+		if numericSearchFields[field] {
+			number, err := strconv.Atoi(value.(string))
+			if err != nil {
+				return "", nil, fmt.Errorf("field %q requires a number", name)
+			}
+			value = number
+		}
+
+		conditions = append(conditions, repository.ObjectCondition{
+			Field:    field,
+			Operator: compare[1],
+			Value:    value,
+		})
+	}
+
+	return field, conditions, nil
+}
+
+// unquote strips the quotes around an XPath literal
+func unquote(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && (value[0] == '\'' || value[0] == '"') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// SearchableSchemaDocument returns the XSD announced in
+// mosListSearchableSchema. It lists every searchable mosObj field with its
+// type and documents the predicates and sorting accepted in searchField.
+func SearchableSchemaDocument() []byte {
+	names := make([]string, 0, len(searchFields))
+	for name := range searchFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var doc strings.Builder
+	doc.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<xsd:schema xmlns:xsd="http://www.w3.org/2001/XMLSchema" targetNamespace="` + SearchableSchema + `" elementFormDefault="qualified">
+	<xsd:annotation>
+		<xsd:documentation>
+			Searchable fields of mosReqObjList. Each searchField XPath names one
+			field below, e.g. /objSlug[.='Fire'], and may hold a predicate made of
+			clauses joined with AND: . = value, . != value, . &lt; value,
+			. &lt;= value, . &gt; value, . &gt;= value or contains(., 'text').
+			Integer fields are compared as numbers. The fields of a searchGroup
+			are ANDed and the groups are ORed. sortByOrder and sortType
+			(ASCENDING or DESCENDING) sort the results. generalSearch matches the
+			text fields.
+		</xsd:documentation>
+	</xsd:annotation>
+	<xsd:element name="mosObj">
+		<xsd:complexType>
+			<xsd:all>
+`)
+	for _, name := range names {
+		fieldType := "xsd:string"
+		if numericSearchFields[searchFields[name]] {
+			fieldType = "xsd:integer"
+		}
+		fmt.Fprintf(&doc, "\t\t\t\t<xsd:element name=%q type=%q minOccurs=\"0\"/>\n", name, fieldType)
+	}
+	doc.WriteString(`			</xsd:all>
+		</xsd:complexType>
+	</xsd:element>
+</xsd:schema>
+`)
+	return []byte(doc.String())
+}
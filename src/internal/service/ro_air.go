@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"airshift/openmos/internal/events"
+	"airshift/openmos/internal/model"
+)
+
+// ErrRunningOrderOnAir is returned for destructive edits to a running order
+// that is ready to air and not marked as an on-air override
+var ErrRunningOrderOnAir = errors.New("running order is ready to air")
+
+// onAirOverrideKey is the context key marking an edit as an on-air override
+type onAirOverrideKey struct{}
+
+// WithOnAirOverride returns a context that lets edits bypass the air-lock
+func WithOnAirOverride(ctx context.Context) context.Context {
+	return context.WithValue(ctx, onAirOverrideKey{}, true)
+}
+
+// IsOnAirOverride reports whether the context marks an on-air override
+func IsOnAirOverride(ctx context.Context) bool {
+	override, _ := ctx.Value(onAirOverrideKey{}).(bool)
+	return override
+}
+
+// SetReadyToAir flags a running order as ready to air, or clears the flag
+func (s *MOSService) SetReadyToAir(ctx context.Context, roID string, ready bool) error {
+	ro, err := s.runningOrderRepo.Get(ctx, roID)
+	if err != nil {
+		return err
+	}
+
+	if ro.ReadyToAir == ready {
+		return nil
+	}
+
+	ro.ReadyToAir = ready
+	ro.UpdatedAt = time.Now()
+
+	err = s.runningOrderRepo.Update(ctx, ro)
+	if err != nil {
+		return fmt.Errorf("failed to update running order: %w", err)
+	}
+
+	// Publish event after successful update
+	if s.eventBus != nil {
+		s.eventBus.Publish(events.Event{
+			Type:    events.RunningOrderAirStateChanged,
+			Payload: roID,
+			Source:  "mos_service",
+		})
+	}
+
+	return nil
+}
+
+// IsReadyToAir reports whether a running order is ready to air
+func (s *MOSService) IsReadyToAir(ctx context.Context, roID string) (bool, error) {
+	ro, err := s.runningOrderRepo.Get(ctx, roID)
+	if err != nil {
+		return false, err
+	}
+
+	return ro.ReadyToAir, nil
+}
+
+// checkAirLock rejects destructive edits to a running order that is ready to
+// air, unless the edit is an on-air override. Deleting, replacing, moving and
+// swapping elements is destructive; inserting elements and updating items or
+// the running order metadata in place is not.
+func checkAirLock(ctx context.Context, ro *model.RunningOrder, operation string) error {
+	switch operation {
+	case OperationReplace, OperationMove, OperationDelete, OperationSwap:
+	default:
+		return nil
+	}
+	if !ro.ReadyToAir || IsOnAirOverride(ctx) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", ErrRunningOrderOnAir, ro.ID)
+}
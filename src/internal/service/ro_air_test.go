@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+
+	"airshift/openmos/internal/model"
+	"airshift/openmos/internal/repository"
+	"airshift/openmos/internal/xml"
+)
+
+// memoryStore keeps running orders, stories and items in memory
+type memoryStore struct {
+	runningOrders map[string]*model.RunningOrder
+	stories       map[string]*model.Story
+	items         map[string]*model.Item
+}
+
+type memoryRunningOrders struct{ *memoryStore }
+type memoryStories struct{ *memoryStore }
+type memoryItems struct{ *memoryStore }
+
+func (m memoryRunningOrders) Create(ctx context.Context, ro *model.RunningOrder) (*model.RunningOrder, error) {
+	m.runningOrders[ro.ID] = ro
+	return ro, nil
+}
+
+func (m memoryRunningOrders) Get(ctx context.Context, id string) (*model.RunningOrder, error) {
+	if ro, ok := m.runningOrders[id]; ok {
+		return ro, nil
+	}
+	return nil, fmt.Errorf("running order %w: %s", repository.ErrNotFound, id)
+}
+
+func (m memoryRunningOrders) Update(ctx context.Context, ro *model.RunningOrder) error {
+	m.runningOrders[ro.ID] = ro
+	return nil
+}
+
+func (m memoryRunningOrders) Delete(ctx context.Context, id string) error {
+	delete(m.runningOrders, id)
+	return nil
+}
+
+func (m memoryRunningOrders) List(ctx context.Context) ([]*model.RunningOrder, error) {
+	var ros []*model.RunningOrder
+	for _, ro := range m.runningOrders {
+		ros = append(ros, ro)
+	}
+	return ros, nil
+}
+
+func (m memoryStories) Create(ctx context.Context, story *model.Story) (*model.Story, error) {
+	m.stories[story.ID] = story
+	return story, nil
+}
+
+func (m memoryStories) Get(ctx context.Context, id string) (*model.Story, error) {
+	if story, ok := m.stories[id]; ok {
+		return story, nil
+	}
+	return nil, fmt.Errorf("story %w: %s", repository.ErrNotFound, id)
+}
+
+func (m memoryStories) Update(ctx context.Context, story *model.Story) error {
+	m.stories[story.ID] = story
+	return nil
+}
+
+func (m memoryStories) Delete(ctx context.Context, id string) error {
+	delete(m.stories, id)
+	return nil
+}
+
+func (m memoryStories) ListByRunningOrder(ctx context.Context, roID string) ([]*model.Story, error) {
+	var stories []*model.Story
+	for _, story := range m.stories {
+		if story.RunningOrderID == roID {
+			stories = append(stories, story)
+		}
+	}
+	sort.Slice(stories, func(i, j int) bool { return stories[i].Order < stories[j].Order })
+	return stories, nil
+}
+
+func (m memoryItems) Create(ctx context.Context, item *model.Item) (*model.Item, error) {
+	m.items[item.ID] = item
+	return item, nil
+}
+
+func (m memoryItems) Get(ctx context.Context, id string) (*model.Item, error) {
+	if item, ok := m.items[id]; ok {
+		return item, nil
+	}
+	return nil, fmt.Errorf("item %w: %s", repository.ErrNotFound, id)
+}
+
+func (m memoryItems) Update(ctx context.Context, item *model.Item) error {
+	m.items[item.ID] = item
+	return nil
+}
+
+func (m memoryItems) Delete(ctx context.Context, id string) error {
+	delete(m.items, id)
+	return nil
+}
+
+func (m memoryItems) ListByStory(ctx context.Context, storyID string) ([]*model.Item, error) {
+	var items []*model.Item
+	for _, item := range m.items {
+		if item.StoryID == storyID {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Order < items[j].Order })
+	return items, nil
+}
+
+// newReadyToAirService returns a service holding running order RO1, ready to
+// air, with stories S1 and S2 of one item each
+func newReadyToAirService(t *testing.T) *MOSService {
+	store := &memoryStore{
+		runningOrders: make(map[string]*model.RunningOrder),
+		stories:       make(map[string]*model.Story),
+		items:         make(map[string]*model.Item),
+	}
+	s := NewMOSService(memoryRunningOrders{store}, memoryStories{store}, memoryItems{store}, nil, nil)
+
+	ctx := context.Background()
+	_, err := s.ProcessRunningOrderInfo(ctx, xml.RunningOrderInfo{
+		ID:   "RO1",
+		Slug: "News",
+		Stories: []xml.StoryInfo{
+			{ID: "S1", Slug: "Opener", Items: []xml.ItemInfo{{ID: "I1", Slug: "Titles"}}},
+			{ID: "S2", Slug: "Weather", Items: []xml.ItemInfo{{ID: "I2", Slug: "Map"}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ProcessRunningOrderInfo() error: %v", err)
+	}
+	if err := s.SetReadyToAir(ctx, "RO1", true); err != nil {
+		t.Fatalf("SetReadyToAir() error: %v", err)
+	}
+	return s
+}
+
+func TestAirLock(t *testing.T) {
+	stories := []xml.StoryInfo{{ID: "S3", Slug: "Sport"}}
+	items := []xml.ItemInfo{{ID: "I3", Slug: "Lower third"}}
+
+	tests := []struct {
+		name   string
+		edit   func(ctx context.Context, s *MOSService) error
+		locked bool
+	}{
+		{"roStoryInsert", func(ctx context.Context, s *MOSService) error {
+			_, err := s.InsertStories(ctx, "RO1", "S2", stories)
+			return err
+		}, false},
+		{"roStoryAppend", func(ctx context.Context, s *MOSService) error {
+			_, err := s.AppendStories(ctx, "RO1", stories)
+			return err
+		}, false},
+		{"roItemInsert", func(ctx context.Context, s *MOSService) error {
+			_, err := s.InsertItems(ctx, "RO1", "S1", "", items)
+			return err
+		}, false},
+		{"mosItemReplace", func(ctx context.Context, s *MOSService) error {
+			_, err := s.ReplaceItemContent(ctx, "RO1", "S1", xml.ItemInfo{ID: "I1", Slug: "New titles"})
+			return err
+		}, false},
+		{"roMetadataReplace", func(ctx context.Context, s *MOSService) error {
+			return s.ReplaceRunningOrderMetadata(ctx, xml.ROMetadataReplace{ROID: "RO1", Slug: "Late news"})
+		}, false},
+		{"roStoryReplace", func(ctx context.Context, s *MOSService) error {
+			_, err := s.ReplaceStory(ctx, "RO1", "S1", stories)
+			return err
+		}, true},
+		{"roStoryMove", func(ctx context.Context, s *MOSService) error {
+			_, err := s.MoveStories(ctx, "RO1", []string{"S2"}, "S1")
+			return err
+		}, true},
+		{"roStorySwap", func(ctx context.Context, s *MOSService) error {
+			_, err := s.SwapStories(ctx, "RO1", "S1", "S2")
+			return err
+		}, true},
+		{"roStoryDelete", func(ctx context.Context, s *MOSService) error {
+			_, err := s.DeleteStories(ctx, "RO1", []string{"S1"})
+			return err
+		}, true},
+		{"roItemReplace", func(ctx context.Context, s *MOSService) error {
+			_, err := s.ReplaceItem(ctx, "RO1", "S1", "I1", items)
+			return err
+		}, true},
+		{"roItemDelete", func(ctx context.Context, s *MOSService) error {
+			_, err := s.DeleteItems(ctx, "RO1", "S1", []string{"I1"})
+			return err
+		}, true},
+		{"roReplace", func(ctx context.Context, s *MOSService) error {
+			_, err := s.ReplaceRunningOrder(ctx, xml.RunningOrderInfo{ID: "RO1", Slug: "News"})
+			return err
+		}, true},
+		{"roDelete", func(ctx context.Context, s *MOSService) error {
+			return s.DeleteRunningOrder(ctx, "RO1")
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.edit(context.Background(), newReadyToAirService(t))
+			if locked := errors.Is(err, ErrRunningOrderOnAir); locked != tt.locked {
+				t.Errorf("error = %v, want locked %v", err, tt.locked)
+			}
+			if !tt.locked && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			// An on-air override always goes through
+			err = tt.edit(WithOnAirOverride(context.Background()), newReadyToAirService(t))
+			if err != nil {
+				t.Errorf("with override: unexpected error: %v", err)
+			}
+		})
+	}
+}
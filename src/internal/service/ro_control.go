@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"airshift/openmos/internal/model"
+	"airshift/openmos/pkg/logger"
+)
+
+// Profile 5 control commands
+const (
+	CommandReady   = "READY"
+	CommandExecute = "EXECUTE"
+	CommandPause   = "PAUSE"
+	CommandStop    = "STOP"
+	CommandSignal  = "SIGNAL"
+)
+
+// controlTransition is the status a command moves an element to and the
+// statuses it may be applied from
+type controlTransition struct {
+	from []model.StatusType
+	to   model.StatusType
+}
+
+// controlTransitions lists the status transitions of the control commands.
+// SIGNAL is a general indicator and changes no status.
+var controlTransitions = map[string]controlTransition{
+	CommandReady: {
+		from: []model.StatusType{model.StatusPending, model.StatusReady, model.StatusPaused, model.StatusCompleted, model.StatusSkipped, model.StatusError},
+		to:   model.StatusReady,
+	},
+	CommandExecute: {
+		from: []model.StatusType{model.StatusPending, model.StatusReady, model.StatusPaused, model.StatusActive},
+		to:   model.StatusActive,
+	},
+	CommandPause: {
+		from: []model.StatusType{model.StatusActive, model.StatusPaused},
+		to:   model.StatusPaused,
+	},
+	CommandStop: {
+		from: []model.StatusType{model.StatusActive, model.StatusPaused, model.StatusCompleted},
+		to:   model.StatusCompleted,
+	},
+}
+
+// allows reports whether the transition may be applied from a status
+func (t controlTransition) allows(status model.StatusType) bool {
+	for _, from := range t.from {
+		if from == status {
+			return true
+		}
+	}
+	return false
+}
+
+// ControlElement applies a Profile 5 control command to an item, to a story
+// and its items, or to every story of a running order when no story is given.
+// Commands with a time in the future are validated now and applied at that time.
+func (s *MOSService) ControlElement(ctx context.Context, roID, storyID, itemID, command string, at time.Time) ([]ElementStatus, error) {
+	command = strings.ToUpper(strings.TrimSpace(command))
+	if _, ok := controlTransitions[command]; !ok && command != CommandSignal {
+		return nil, fmt.Errorf("unsupported control command %q", command)
+	}
+	if itemID != "" && storyID == "" {
+		return nil, fmt.Errorf("missing story for item %s", itemID)
+	}
+
+	// Validate the target before applying or scheduling the command
+	_, err := s.runningOrderRepo.Get(ctx, roID)
+	if err != nil {
+		return nil, err
+	}
+	if storyID != "" {
+		story, err := s.storyRepo.Get(ctx, storyID)
+		if err != nil {
+			return nil, err
+		}
+		if story.RunningOrderID != roID {
+			return nil, fmt.Errorf("story %s not found in running order %s", storyID, roID)
+		}
+	}
+	if itemID != "" {
+		_, err := s.itemRepo.Get(ctx, itemKey(storyID, itemID))
+		if err != nil {
+			return nil, fmt.Errorf("item %s not found in story %s", itemID, storyID)
+		}
+	}
+
+	if command == CommandSignal {
+		logger.Infof("Received SIGNAL for RO %s story %s item %s", roID, storyID, itemID)
+		return acceptedTarget(storyID, itemID), nil
+	}
+
+	if delay := time.Until(at); !at.IsZero() && delay > 0 {
+		logger.Infof("Scheduling %s for RO %s story %s item %s in %s", command, roID, storyID, itemID, delay)
+
+		scheduled := context.WithoutCancel(ctx)
+		s.controls.schedule(controlKey{roID, storyID, itemID}, delay, func() {
+			results, err := s.applyControl(scheduled, roID, storyID, itemID, command)
+			if err != nil {
+				logger.Errorf("Failed to apply scheduled %s to RO %s: %v", command, roID, err)
+				return
+			}
+			if SummarizeStatus(results) != ElementOK {
+				logger.Warningf("Scheduled %s for RO %s partly failed: %s", command, roID, FailureReasons(results))
+			}
+		})
+
+		return acceptedTarget(storyID, itemID), nil
+	}
+
+	return s.applyControl(ctx, roID, storyID, itemID, command)
+}
+
+// applyControl applies the status transition of a command to its target
+func (s *MOSService) applyControl(ctx context.Context, roID, storyID, itemID, command string) ([]ElementStatus, error) {
+	transition := controlTransitions[command]
+
+	if itemID != "" {
+		item, err := s.itemRepo.Get(ctx, itemKey(storyID, itemID))
+		if err != nil {
+			return nil, err
+		}
+
+		return []ElementStatus{s.transitionItem(ctx, roID, item, command, transition)}, nil
+	}
+
+	var stories []*model.Story
+	if storyID != "" {
+		story, err := s.storyRepo.Get(ctx, storyID)
+		if err != nil {
+			return nil, err
+		}
+		stories = []*model.Story{story}
+	} else {
+		var err error
+		stories, err = s.storyRepo.ListByRunningOrder(ctx, roID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list stories: %w", err)
+		}
+	}
+
+	var results []ElementStatus
+	for _, story := range stories {
+		if !transition.allows(story.Status) {
+			results = append(results, elementFailed(story.ID, fmt.Errorf("cannot %s story %s while %s", command, story.ID, story.Status)))
+			continue
+		}
+
+		err := s.updateStoryStatus(ctx, roID, story, transition.to)
+		if err != nil {
+			results = append(results, elementFailed(story.ID, err))
+			continue
+		}
+		results = append(results, elementOK(story.ID))
+
+		// Carry the command down to the items that accept it
+		items, err := s.itemRepo.ListByStory(ctx, story.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list items for story %s: %w", story.ID, err)
+		}
+		for _, item := range items {
+			if transition.allows(item.Status) {
+				results = append(results, s.transitionItem(ctx, roID, item, command, transition))
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// transitionItem applies a command transition to a single item
+func (s *MOSService) transitionItem(ctx context.Context, roID string, item *model.Item, command string, transition controlTransition) ElementStatus {
+	if !transition.allows(item.Status) {
+		return itemFailed(item.StoryID, item.ItemID, fmt.Errorf("cannot %s item %s while %s", command, item.ItemID, item.Status))
+	}
+
+	err := s.updateItemStatus(ctx, roID, item, transition.to)
+	if err != nil {
+		return itemFailed(item.StoryID, item.ItemID, err)
+	}
+
+	return itemOK(item.StoryID, item.ItemID)
+}
+
+// acceptedTarget returns the result for a command accepted without changing
+// any status yet. Running order level commands report no element.
+func acceptedTarget(storyID, itemID string) []ElementStatus {
+	if storyID == "" {
+		return nil
+	}
+	return []ElementStatus{itemOK(storyID, itemID)}
+}
+
+// controlKey identifies the element a scheduled command applies to
+type controlKey struct {
+	roID    string
+	storyID string
+	itemID  string
+}
+
+// controlSchedule tracks the control commands waiting for their time. A new
+// command for an element replaces the one pending for it.
+type controlSchedule struct {
+	timers map[controlKey]*time.Timer
+	closed bool
+	mu     sync.Mutex
+}
+
+// schedule runs apply after the delay unless it is cancelled first
+func (c *controlSchedule) schedule(key controlKey, delay time.Duration, apply func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+	if c.timers == nil {
+		c.timers = make(map[controlKey]*time.Timer)
+	}
+	if pending, ok := c.timers[key]; ok {
+		pending.Stop()
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(delay, func() {
+		c.mu.Lock()
+		current := c.timers[key] == timer
+		if current {
+			delete(c.timers, key)
+		}
+		c.mu.Unlock()
+
+		if current {
+			apply()
+		}
+	})
+	c.timers[key] = timer
+}
+
+// cancelRunningOrder stops the commands pending for a running order
+func (c *controlSchedule) cancelRunningOrder(roID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, timer := range c.timers {
+		if key.roID == roID {
+			timer.Stop()
+			delete(c.timers, key)
+			logger.Infof("Cancelled scheduled command for RO %s story %s item %s", roID, key.storyID, key.itemID)
+		}
+	}
+}
+
+// stop cancels every pending command and refuses new ones
+func (c *controlSchedule) stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, timer := range c.timers {
+		timer.Stop()
+		delete(c.timers, key)
+	}
+	c.closed = true
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"airshift/openmos/internal/xml"
+)
+
+// ApplyElementAction applies an roElementAction to the stories of a running
+// order, or to the items of the target story when the source carries items
+func (s *MOSService) ApplyElementAction(ctx context.Context, action xml.ROElementAction) ([]ElementStatus, error) {
+	operation := strings.ToUpper(action.Operation)
+
+	if action.Elements.TargetsItems() {
+		return s.applyItemAction(ctx, operation, action)
+	}
+
+	return s.applyStoryAction(ctx, operation, action)
+}
+
+// applyStoryAction applies an roElementAction whose source lists stories
+func (s *MOSService) applyStoryAction(ctx context.Context, operation string, action xml.ROElementAction) ([]ElementStatus, error) {
+	roID := action.ROID
+	target := action.Target.StoryID
+	source := action.Elements
+
+	switch operation {
+	case OperationInsert:
+		if len(source.Stories) == 0 {
+			return nil, fmt.Errorf("no stories to insert")
+		}
+		return s.InsertStories(ctx, roID, target, source.Stories)
+
+	case OperationReplace:
+		if target == "" {
+			return nil, fmt.Errorf("missing target story to replace")
+		}
+		if len(source.Stories) == 0 {
+			return nil, fmt.Errorf("no replacement stories for story %s", target)
+		}
+		return s.ReplaceStory(ctx, roID, target, source.Stories)
+
+	case OperationMove:
+		return s.MoveStories(ctx, roID, source.StoryIDs, target)
+
+	case OperationDelete:
+		if len(source.StoryIDs) == 0 {
+			return nil, fmt.Errorf("no stories to delete")
+		}
+		return s.DeleteStories(ctx, roID, source.StoryIDs)
+
+	case OperationSwap:
+		if len(source.StoryIDs) != 2 {
+			return nil, fmt.Errorf("exactly two stories are required to swap")
+		}
+		return s.SwapStories(ctx, roID, source.StoryIDs[0], source.StoryIDs[1])
+
+	default:
+		return nil, fmt.Errorf("unsupported element action operation %q", action.Operation)
+	}
+}
+
+// applyItemAction applies an roElementAction whose source lists items of the
+// target story
+func (s *MOSService) applyItemAction(ctx context.Context, operation string, action xml.ROElementAction) ([]ElementStatus, error) {
+	roID := action.ROID
+	storyID := action.Target.StoryID
+	target := action.Target.ItemID
+	source := action.Elements
+
+	if storyID == "" {
+		return nil, fmt.Errorf("missing target story for item action")
+	}
+
+	switch operation {
+	case OperationInsert:
+		if len(source.Items) == 0 {
+			return nil, fmt.Errorf("no items to insert")
+		}
+		return s.InsertItems(ctx, roID, storyID, target, source.Items)
+
+	case OperationReplace:
+		if target == "" {
+			return nil, fmt.Errorf("missing target item to replace in story %s", storyID)
+		}
+		if len(source.Items) == 0 {
+			return nil, fmt.Errorf("no replacement items for item %s", target)
+		}
+		return s.ReplaceItem(ctx, roID, storyID, target, source.Items)
+
+	case OperationMove:
+		return s.MoveItems(ctx, roID, storyID, source.ItemIDs, target)
+
+	case OperationDelete:
+		if len(source.ItemIDs) == 0 {
+			return nil, fmt.Errorf("no items to delete")
+		}
+		return s.DeleteItems(ctx, roID, storyID, source.ItemIDs)
+
+	case OperationSwap:
+		if len(source.ItemIDs) != 2 {
+			return nil, fmt.Errorf("exactly two items are required to swap")
+		}
+		return s.SwapItems(ctx, roID, storyID, source.ItemIDs[0], source.ItemIDs[1])
+
+	default:
+		return nil, fmt.Errorf("unsupported element action operation %q", action.Operation)
+	}
+}
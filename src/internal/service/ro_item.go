@@ -0,0 +1,338 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"airshift/openmos/internal/model"
+	"airshift/openmos/internal/xml"
+)
+
+// itemEdit rewrites the item sequence of a story. It returns the new sequence
+// and the per-item results, or an error that rejects the whole message.
+type itemEdit func(items []*model.Item) ([]*model.Item, []ElementStatus, error)
+
+// itemOK returns a successful result for an item
+func itemOK(storyID, itemID string) ElementStatus {
+	return ElementStatus{StoryID: storyID, ItemID: itemID, Status: ElementOK}
+}
+
+// itemFailed returns a failed result for an item
+func itemFailed(storyID, itemID string, err error) ElementStatus {
+	return ElementStatus{StoryID: storyID, ItemID: itemID, Status: FailureStatus(err), Err: err}
+}
+
+// InsertItems inserts items into a story before the target item, or appends
+// them when the target is empty
+func (s *MOSService) InsertItems(ctx context.Context, roID, storyID, targetItemID string, itemInfos []xml.ItemInfo) ([]ElementStatus, error) {
+	return s.editItems(ctx, roID, storyID, OperationInsert, func(items []*model.Item) ([]*model.Item, []ElementStatus, error) {
+		position := len(items)
+		if targetItemID != "" {
+			position = indexOfItem(items, targetItemID)
+			if position == -1 {
+				return nil, nil, fmt.Errorf("item %s not found in story %s", targetItemID, storyID)
+			}
+		}
+
+		inserted, results, err := s.storeItems(ctx, storyID, items, itemInfos, "")
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return insertItems(items, position, inserted), results, nil
+	})
+}
+
+// ReplaceItem replaces the target item of a story with one or more items
+func (s *MOSService) ReplaceItem(ctx context.Context, roID, storyID, targetItemID string, itemInfos []xml.ItemInfo) ([]ElementStatus, error) {
+	return s.editItems(ctx, roID, storyID, OperationReplace, func(items []*model.Item) ([]*model.Item, []ElementStatus, error) {
+		position := indexOfItem(items, targetItemID)
+		if position == -1 {
+			return nil, nil, fmt.Errorf("item %s not found in story %s", targetItemID, storyID)
+		}
+
+		replacements, results, err := s.storeItems(ctx, storyID, items, itemInfos, targetItemID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// Delete the target unless it is one of its own replacements
+		if indexOfItem(replacements, targetItemID) == -1 {
+			err = s.itemRepo.Delete(ctx, items[position].ID)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to delete item %s: %w", targetItemID, err)
+			}
+		}
+
+		remaining := append(items[:position:position], items[position+1:]...)
+		return insertItems(remaining, position, replacements), results, nil
+	})
+}
+
+// MoveItems moves items of a story, in the given order, before the target
+// item, or to the end of the story when the target is empty
+func (s *MOSService) MoveItems(ctx context.Context, roID, storyID string, itemIDs []string, targetItemID string) ([]ElementStatus, error) {
+	return s.editItems(ctx, roID, storyID, OperationMove, func(items []*model.Item) ([]*model.Item, []ElementStatus, error) {
+		if len(itemIDs) == 0 {
+			return nil, nil, fmt.Errorf("no items to move")
+		}
+		if targetItemID != "" && indexOfItem(items, targetItemID) == -1 {
+			return nil, nil, fmt.Errorf("item %s not found in story %s", targetItemID, storyID)
+		}
+
+		moving := make(map[string]bool, len(itemIDs))
+		for _, itemID := range itemIDs {
+			if itemID == targetItemID {
+				return nil, nil, fmt.Errorf("cannot move item %s before itself", itemID)
+			}
+			moving[itemID] = true
+		}
+
+		// Take the moved items out of the sequence
+		remaining := make([]*model.Item, 0, len(items))
+		for _, item := range items {
+			if !moving[item.ItemID] {
+				remaining = append(remaining, item)
+			}
+		}
+
+		moved := make([]*model.Item, 0, len(itemIDs))
+		results := make([]ElementStatus, 0, len(itemIDs))
+		for _, itemID := range itemIDs {
+			index := indexOfItem(items, itemID)
+			if index == -1 {
+				results = append(results, itemFailed(storyID, itemID, fmt.Errorf("item %s not found", itemID)))
+				continue
+			}
+			if indexOfItem(moved, itemID) != -1 {
+				continue
+			}
+
+			moved = append(moved, items[index])
+			results = append(results, itemOK(storyID, itemID))
+		}
+
+		position := len(remaining)
+		if targetItemID != "" {
+			position = indexOfItem(remaining, targetItemID)
+		}
+
+		return insertItems(remaining, position, moved), results, nil
+	})
+}
+
+// SwapItems swaps the positions of two items in a story
+func (s *MOSService) SwapItems(ctx context.Context, roID, storyID, firstID, secondID string) ([]ElementStatus, error) {
+	return s.editItems(ctx, roID, storyID, OperationSwap, func(items []*model.Item) ([]*model.Item, []ElementStatus, error) {
+		first := indexOfItem(items, firstID)
+		if first == -1 {
+			return nil, nil, fmt.Errorf("item %s not found in story %s", firstID, storyID)
+		}
+		second := indexOfItem(items, secondID)
+		if second == -1 {
+			return nil, nil, fmt.Errorf("item %s not found in story %s", secondID, storyID)
+		}
+
+		items[first], items[second] = items[second], items[first]
+
+		return items, []ElementStatus{itemOK(storyID, firstID), itemOK(storyID, secondID)}, nil
+	})
+}
+
+// DeleteItems deletes items from a story
+func (s *MOSService) DeleteItems(ctx context.Context, roID, storyID string, itemIDs []string) ([]ElementStatus, error) {
+	return s.editItems(ctx, roID, storyID, OperationDelete, func(items []*model.Item) ([]*model.Item, []ElementStatus, error) {
+		results := make([]ElementStatus, 0, len(itemIDs))
+		for _, itemID := range itemIDs {
+			index := indexOfItem(items, itemID)
+			if index == -1 {
+				results = append(results, itemFailed(storyID, itemID, fmt.Errorf("item %s not found", itemID)))
+				continue
+			}
+
+			err := s.itemRepo.Delete(ctx, items[index].ID)
+			if err != nil {
+				results = append(results, itemFailed(storyID, itemID, err))
+				continue
+			}
+
+			items = append(items[:index], items[index+1:]...)
+			results = append(results, itemOK(storyID, itemID))
+		}
+
+		return items, results, nil
+	})
+}
+
+// ReplaceItemContent replaces the content of an item in place, keeping its
+// position in the story. It applies mosItemReplace from the MOS owning the item.
+func (s *MOSService) ReplaceItemContent(ctx context.Context, roID, storyID string, itemInfo xml.ItemInfo) ([]ElementStatus, error) {
+	return s.editItems(ctx, roID, storyID, OperationUpdate, func(items []*model.Item) ([]*model.Item, []ElementStatus, error) {
+		index := indexOfItem(items, itemInfo.ID)
+		if index == -1 {
+			return nil, nil, fmt.Errorf("item %s not found in story %s", itemInfo.ID, storyID)
+		}
+
+		item := items[index]
+		applyItemInfo(item, itemInfo)
+
+		err := s.itemRepo.Update(ctx, item)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to update item %s: %w", itemInfo.ID, err)
+		}
+
+		result := itemOK(storyID, itemInfo.ID)
+		result.ObjID = item.ObjectID
+
+		return items, []ElementStatus{result}, nil
+	})
+}
+
+// editItems applies an edit to the item sequence of a story, renumbers the
+// items and recomputes the story and running order durations
+func (s *MOSService) editItems(ctx context.Context, roID, storyID, operation string, edit itemEdit) ([]ElementStatus, error) {
+	ro, err := s.runningOrderRepo.Get(ctx, roID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkAirLock(ctx, ro, operation)
+	if err != nil {
+		return nil, err
+	}
+
+	story, err := s.storyRepo.Get(ctx, storyID)
+	if err != nil {
+		return nil, err
+	}
+	if story.RunningOrderID != roID {
+		return nil, fmt.Errorf("story %s not found in running order %s", storyID, roID)
+	}
+
+	items, err := s.itemRepo.ListByStory(ctx, storyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list items for story %s: %w", storyID, err)
+	}
+
+	// Remember the current order to only save items that moved
+	before := make(map[string]int, len(items))
+	for _, item := range items {
+		before[item.ID] = item.Order
+	}
+
+	items, results, err := edit(items)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, item := range items {
+		item.Order = i + 1
+
+		order, ok := before[item.ID]
+		if ok && order == item.Order {
+			continue
+		}
+
+		err = s.itemRepo.Update(ctx, item)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update item %s: %w", item.ID, err)
+		}
+	}
+
+	// Recompute the story duration from its items
+	story.Duration = storyDuration("", items)
+	story.UpdatedAt = time.Now()
+
+	err = s.storyRepo.Update(ctx, story)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update story %s: %w", storyID, err)
+	}
+
+	stories, err := s.storyRepo.ListByRunningOrder(ctx, roID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stories: %w", err)
+	}
+
+	summarizeRunningOrder(ro, stories)
+	ro.UpdatedAt = time.Now()
+
+	err = s.runningOrderRepo.Update(ctx, ro)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update running order: %w", err)
+	}
+
+	s.publishElementChanges(roID, operation, results)
+
+	return results, nil
+}
+
+// storeItems creates or updates incoming items. Items already in the story
+// are rejected, except for the item being replaced.
+func (s *MOSService) storeItems(ctx context.Context, storyID string, current []*model.Item, itemInfos []xml.ItemInfo, replacing string) ([]*model.Item, []ElementStatus, error) {
+	stored := make([]*model.Item, 0, len(itemInfos))
+	results := make([]ElementStatus, 0, len(itemInfos))
+
+	for _, itemInfo := range itemInfos {
+		var item *model.Item
+		if index := indexOfItem(current, itemInfo.ID); index != -1 {
+			if itemInfo.ID != replacing {
+				results = append(results, itemFailed(storyID, itemInfo.ID, fmt.Errorf("item %s already exists in story %s", itemInfo.ID, storyID)))
+				continue
+			}
+			item = current[index]
+		}
+		if indexOfItem(stored, itemInfo.ID) != -1 {
+			results = append(results, itemFailed(storyID, itemInfo.ID, fmt.Errorf("duplicate item %s", itemInfo.ID)))
+			continue
+		}
+
+		isNew := item == nil
+		if isNew {
+			item = &model.Item{
+				ID:      itemKey(storyID, itemInfo.ID),
+				ItemID:  itemInfo.ID,
+				StoryID: storyID,
+				Status:  model.StatusPending,
+			}
+		}
+
+		applyItemInfo(item, itemInfo)
+
+		if isNew {
+			_, err := s.itemRepo.Create(ctx, item)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to create item: %w", err)
+			}
+		} else {
+			err := s.itemRepo.Update(ctx, item)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to update item: %w", err)
+			}
+		}
+
+		stored = append(stored, item)
+		results = append(results, itemOK(storyID, itemInfo.ID))
+	}
+
+	return stored, results, nil
+}
+
+// indexOfItem returns the position of an item, by MOS item ID, or -1
+func indexOfItem(items []*model.Item, itemID string) int {
+	for i, item := range items {
+		if item.ItemID == itemID {
+			return i
+		}
+	}
+	return -1
+}
+
+// insertItems returns a new sequence with items inserted at position
+func insertItems(items []*model.Item, position int, inserted []*model.Item) []*model.Item {
+	result := make([]*model.Item, 0, len(items)+len(inserted))
+	result = append(result, items[:position]...)
+	result = append(result, inserted...)
+	result = append(result, items[position:]...)
+	return result
+}
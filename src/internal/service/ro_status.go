@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"airshift/openmos/internal/events"
+	"airshift/openmos/internal/model"
+)
+
+// SetStoryStatus changes the status of a story in a running order and
+// publishes the change so it can be reported to the owning NCS
+func (s *MOSService) SetStoryStatus(ctx context.Context, roID, storyID string, status model.StatusType) error {
+	story, err := s.storyRepo.Get(ctx, storyID)
+	if err != nil {
+		return err
+	}
+	if story.RunningOrderID != roID {
+		return fmt.Errorf("story %s not found in running order %s", storyID, roID)
+	}
+
+	return s.updateStoryStatus(ctx, roID, story, status)
+}
+
+// SetItemStatus changes the status of an item in a story and publishes the
+// change so it can be reported to the owning NCS
+func (s *MOSService) SetItemStatus(ctx context.Context, roID, storyID, itemID string, status model.StatusType) error {
+	story, err := s.storyRepo.Get(ctx, storyID)
+	if err != nil {
+		return err
+	}
+	if story.RunningOrderID != roID {
+		return fmt.Errorf("story %s not found in running order %s", storyID, roID)
+	}
+
+	item, err := s.itemRepo.Get(ctx, itemKey(storyID, itemID))
+	if err != nil {
+		return err
+	}
+
+	return s.updateItemStatus(ctx, roID, item, status)
+}
+
+// updateStoryStatus saves a new story status and publishes the change
+func (s *MOSService) updateStoryStatus(ctx context.Context, roID string, story *model.Story, status model.StatusType) error {
+	if story.Status == status {
+		return nil
+	}
+
+	story.Status = status
+	story.UpdatedAt = time.Now()
+
+	err := s.storyRepo.Update(ctx, story)
+	if err != nil {
+		return fmt.Errorf("failed to update story %s: %w", story.ID, err)
+	}
+
+	s.publishStatusChange(events.StatusChange{
+		ROID:    roID,
+		StoryID: story.ID,
+		Status:  string(status),
+		Time:    story.UpdatedAt,
+	})
+
+	return nil
+}
+
+// updateItemStatus saves a new item status and publishes the change
+func (s *MOSService) updateItemStatus(ctx context.Context, roID string, item *model.Item, status model.StatusType) error {
+	if item.Status == status {
+		return nil
+	}
+
+	item.Status = status
+	item.UpdatedAt = time.Now()
+
+	err := s.itemRepo.Update(ctx, item)
+	if err != nil {
+		return fmt.Errorf("failed to update item %s: %w", item.ItemID, err)
+	}
+
+	s.publishStatusChange(events.StatusChange{
+		ROID:        roID,
+		StoryID:     item.StoryID,
+		ItemID:      item.ItemID,
+		ObjID:       item.ObjectID,
+		ItemChannel: item.Channel,
+		Status:      string(status),
+		Time:        item.UpdatedAt,
+	})
+
+	return nil
+}
+
+// publishStatusChange publishes a story or item status change
+func (s *MOSService) publishStatusChange(change events.StatusChange) {
+	if s.eventBus == nil {
+		return
+	}
+
+	s.eventBus.Publish(events.Event{
+		Type:    events.ElementStatusChanged,
+		Payload: change,
+		Source:  "mos_service",
+	})
+}
@@ -0,0 +1,414 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"airshift/openmos/internal/events"
+	"airshift/openmos/internal/model"
+	"airshift/openmos/internal/xml"
+)
+
+// Element status values reported back to the NCS
+const (
+	ElementOK   = "OK"
+	ElementNACK = "NACK"
+)
+
+// Operations applied to running order elements
+const (
+	OperationInsert  = "INSERT"
+	OperationReplace = "REPLACE"
+	OperationMove    = "MOVE"
+	OperationDelete  = "DELETE"
+	OperationSwap    = "SWAP"
+	OperationUpdate  = "UPDATE"
+)
+
+// ElementStatus reports the outcome of a change to a single running order element
+type ElementStatus struct {
+	StoryID     string
+	ItemID      string
+	ObjID       string
+	ItemChannel string
+	Status      string
+	Err         error
+}
+
+// maxStatusLength is the longest roStatus or element status the MOS schema allows
+const maxStatusLength = 128
+
+// SummarizeStatus returns the overall roStatus for a set of element results:
+// "OK" when every element succeeded, otherwise "NACK". The reasons are
+// reported in the status of each failed element.
+func SummarizeStatus(results []ElementStatus) string {
+	for _, result := range results {
+		if result.Err != nil {
+			return ElementNACK
+		}
+	}
+	return ElementOK
+}
+
+// FailureReasons joins the errors of the failed elements, for logging
+func FailureReasons(results []ElementStatus) string {
+	var failures []string
+	for _, result := range results {
+		if result.Err != nil {
+			failures = append(failures, result.Err.Error())
+		}
+	}
+	return strings.Join(failures, "; ")
+}
+
+// FailureStatus returns the status of an element rejected with an error
+func FailureStatus(err error) string {
+	return TruncateStatus(fmt.Sprintf("%s: %v", ElementNACK, err))
+}
+
+// TruncateStatus cuts a status to the length the MOS schema allows
+func TruncateStatus(status string) string {
+	runes := []rune(status)
+	if len(runes) <= maxStatusLength {
+		return status
+	}
+	return string(runes[:maxStatusLength])
+}
+
+// elementOK returns a successful result for a story
+func elementOK(storyID string) ElementStatus {
+	return ElementStatus{StoryID: storyID, Status: ElementOK}
+}
+
+// elementFailed returns a failed result for a story
+func elementFailed(storyID string, err error) ElementStatus {
+	return ElementStatus{StoryID: storyID, Status: FailureStatus(err), Err: err}
+}
+
+// storyEdit rewrites the story sequence of a running order. It returns the new
+// sequence and the per-story results, or an error that rejects the whole message.
+type storyEdit func(stories []*model.Story) ([]*model.Story, []ElementStatus, error)
+
+// InsertStories inserts stories before the target story, or appends them when
+// the target is empty
+func (s *MOSService) InsertStories(ctx context.Context, roID, targetID string, storyInfos []xml.StoryInfo) ([]ElementStatus, error) {
+	return s.editStories(ctx, roID, OperationInsert, func(stories []*model.Story) ([]*model.Story, []ElementStatus, error) {
+		position := len(stories)
+		if targetID != "" {
+			position = indexOfStory(stories, targetID)
+			if position == -1 {
+				return nil, nil, fmt.Errorf("story %s not found in running order %s", targetID, roID)
+			}
+		}
+
+		inserted, results, err := s.storeStories(ctx, roID, stories, storyInfos, "")
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return insertStories(stories, position, inserted), results, nil
+	})
+}
+
+// AppendStories appends stories to the end of a running order
+func (s *MOSService) AppendStories(ctx context.Context, roID string, storyInfos []xml.StoryInfo) ([]ElementStatus, error) {
+	return s.InsertStories(ctx, roID, "", storyInfos)
+}
+
+// ReplaceStory replaces the target story with one or more stories
+func (s *MOSService) ReplaceStory(ctx context.Context, roID, targetID string, storyInfos []xml.StoryInfo) ([]ElementStatus, error) {
+	return s.editStories(ctx, roID, OperationReplace, func(stories []*model.Story) ([]*model.Story, []ElementStatus, error) {
+		position := indexOfStory(stories, targetID)
+		if position == -1 {
+			return nil, nil, fmt.Errorf("story %s not found in running order %s", targetID, roID)
+		}
+
+		replacements, results, err := s.storeStories(ctx, roID, stories, storyInfos, targetID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// Delete the target unless it is one of its own replacements
+		if indexOfStory(replacements, targetID) == -1 {
+			err = s.deleteStoryTree(ctx, targetID)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		remaining := append(stories[:position:position], stories[position+1:]...)
+		return insertStories(remaining, position, replacements), results, nil
+	})
+}
+
+// MoveStories moves stories, in the given order, before the target story, or
+// to the end of the running order when the target is empty
+func (s *MOSService) MoveStories(ctx context.Context, roID string, storyIDs []string, targetID string) ([]ElementStatus, error) {
+	return s.editStories(ctx, roID, OperationMove, func(stories []*model.Story) ([]*model.Story, []ElementStatus, error) {
+		if len(storyIDs) == 0 {
+			return nil, nil, fmt.Errorf("no stories to move")
+		}
+		if targetID != "" && indexOfStory(stories, targetID) == -1 {
+			return nil, nil, fmt.Errorf("story %s not found in running order %s", targetID, roID)
+		}
+
+		moving := make(map[string]bool, len(storyIDs))
+		for _, storyID := range storyIDs {
+			if storyID == targetID {
+				return nil, nil, fmt.Errorf("cannot move story %s before itself", storyID)
+			}
+			moving[storyID] = true
+		}
+
+		// Take the moved stories out of the sequence
+		remaining := make([]*model.Story, 0, len(stories))
+		for _, story := range stories {
+			if !moving[story.ID] {
+				remaining = append(remaining, story)
+			}
+		}
+
+		moved := make([]*model.Story, 0, len(storyIDs))
+		results := make([]ElementStatus, 0, len(storyIDs))
+		for _, storyID := range storyIDs {
+			index := indexOfStory(stories, storyID)
+			if index == -1 {
+				results = append(results, elementFailed(storyID, fmt.Errorf("story %s not found", storyID)))
+				continue
+			}
+			if indexOfStory(moved, storyID) != -1 {
+				continue
+			}
+
+			moved = append(moved, stories[index])
+			results = append(results, elementOK(storyID))
+		}
+
+		position := len(remaining)
+		if targetID != "" {
+			position = indexOfStory(remaining, targetID)
+		}
+
+		return insertStories(remaining, position, moved), results, nil
+	})
+}
+
+// SwapStories swaps the positions of two stories
+func (s *MOSService) SwapStories(ctx context.Context, roID, firstID, secondID string) ([]ElementStatus, error) {
+	return s.editStories(ctx, roID, OperationSwap, func(stories []*model.Story) ([]*model.Story, []ElementStatus, error) {
+		first := indexOfStory(stories, firstID)
+		if first == -1 {
+			return nil, nil, fmt.Errorf("story %s not found in running order %s", firstID, roID)
+		}
+		second := indexOfStory(stories, secondID)
+		if second == -1 {
+			return nil, nil, fmt.Errorf("story %s not found in running order %s", secondID, roID)
+		}
+
+		stories[first], stories[second] = stories[second], stories[first]
+
+		return stories, []ElementStatus{elementOK(firstID), elementOK(secondID)}, nil
+	})
+}
+
+// DeleteStories deletes stories and their items from a running order
+func (s *MOSService) DeleteStories(ctx context.Context, roID string, storyIDs []string) ([]ElementStatus, error) {
+	return s.editStories(ctx, roID, OperationDelete, func(stories []*model.Story) ([]*model.Story, []ElementStatus, error) {
+		results := make([]ElementStatus, 0, len(storyIDs))
+		for _, storyID := range storyIDs {
+			index := indexOfStory(stories, storyID)
+			if index == -1 {
+				results = append(results, elementFailed(storyID, fmt.Errorf("story %s not found", storyID)))
+				continue
+			}
+
+			err := s.deleteStoryTree(ctx, storyID)
+			if err != nil {
+				results = append(results, elementFailed(storyID, err))
+				continue
+			}
+
+			stories = append(stories[:index], stories[index+1:]...)
+			results = append(results, elementOK(storyID))
+		}
+
+		return stories, results, nil
+	})
+}
+
+// editStories applies an edit to the story sequence of a running order, then
+// relinks the stories and saves those whose position changed
+func (s *MOSService) editStories(ctx context.Context, roID, operation string, edit storyEdit) ([]ElementStatus, error) {
+	ro, err := s.runningOrderRepo.Get(ctx, roID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkAirLock(ctx, ro, operation)
+	if err != nil {
+		return nil, err
+	}
+
+	stories, err := s.storyRepo.ListByRunningOrder(ctx, roID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stories: %w", err)
+	}
+
+	// Remember the current links to only save stories that changed
+	type storyLinks struct {
+		order      int
+		previousID string
+		nextID     string
+	}
+	before := make(map[string]storyLinks, len(stories))
+	for _, story := range stories {
+		before[story.ID] = storyLinks{story.Order, story.PreviousID, story.NextID}
+	}
+
+	stories, results, err := edit(stories)
+	if err != nil {
+		return nil, err
+	}
+
+	linkStories(stories)
+
+	for _, story := range stories {
+		links, ok := before[story.ID]
+		if ok && links == (storyLinks{story.Order, story.PreviousID, story.NextID}) {
+			continue
+		}
+
+		err = s.storyRepo.Update(ctx, story)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update story %s: %w", story.ID, err)
+		}
+	}
+
+	summarizeRunningOrder(ro, stories)
+	ro.UpdatedAt = time.Now()
+
+	err = s.runningOrderRepo.Update(ctx, ro)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update running order: %w", err)
+	}
+
+	s.publishElementChanges(roID, operation, results)
+
+	return results, nil
+}
+
+// publishElementChanges publishes an event for every element changed
+// successfully, followed by the running order update. Item changes are also
+// published as ItemChanged.
+func (s *MOSService) publishElementChanges(roID, operation string, results []ElementStatus) {
+	if s.eventBus == nil {
+		return
+	}
+
+	for _, result := range results {
+		if result.Err != nil {
+			continue
+		}
+
+		change := events.ElementChange{
+			Operation: operation,
+			ROID:      roID,
+			StoryID:   result.StoryID,
+			ItemID:    result.ItemID,
+		}
+
+		s.eventBus.Publish(events.Event{
+			Type:    events.RunningOrderElementChanged,
+			Payload: change,
+			Source:  "mos_service",
+		})
+
+		if result.ItemID != "" {
+			s.eventBus.Publish(events.Event{
+				Type:    events.ItemChanged,
+				Payload: change,
+				Source:  "mos_service",
+			})
+		}
+	}
+
+	s.eventBus.Publish(events.Event{
+		Type:    events.RunningOrderUpdated,
+		Payload: roID,
+		Source:  "mos_service",
+	})
+}
+
+// storeStories creates or updates incoming stories. Stories already in the
+// running order are rejected, except for the story being replaced.
+func (s *MOSService) storeStories(ctx context.Context, roID string, current []*model.Story, storyInfos []xml.StoryInfo, replacing string) ([]*model.Story, []ElementStatus, error) {
+	stored := make([]*model.Story, 0, len(storyInfos))
+	results := make([]ElementStatus, 0, len(storyInfos))
+
+	for _, storyInfo := range storyInfos {
+		var existing *model.Story
+		if index := indexOfStory(current, storyInfo.ID); index != -1 {
+			if storyInfo.ID != replacing {
+				results = append(results, elementFailed(storyInfo.ID, fmt.Errorf("story %s already exists in running order %s", storyInfo.ID, roID)))
+				continue
+			}
+			existing = current[index]
+		}
+		if indexOfStory(stored, storyInfo.ID) != -1 {
+			results = append(results, elementFailed(storyInfo.ID, fmt.Errorf("duplicate story %s", storyInfo.ID)))
+			continue
+		}
+		if failures := validateStoryInfo(storyInfo); len(failures) > 0 {
+			results = append(results, failures...)
+			continue
+		}
+
+		story, isNew, err := s.buildStory(ctx, roID, existing, storyInfo)
+		if errors.Is(err, errStoryInOtherRunningOrder) {
+			results = append(results, elementFailed(storyInfo.ID, err))
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if isNew {
+			_, err = s.storyRepo.Create(ctx, story)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to create story: %w", err)
+			}
+		} else {
+			err = s.storyRepo.Update(ctx, story)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to update story: %w", err)
+			}
+		}
+
+		stored = append(stored, story)
+		results = append(results, elementOK(story.ID))
+	}
+
+	return stored, results, nil
+}
+
+// indexOfStory returns the position of a story in a sequence, or -1
+func indexOfStory(stories []*model.Story, storyID string) int {
+	for i, story := range stories {
+		if story.ID == storyID {
+			return i
+		}
+	}
+	return -1
+}
+
+// insertStories returns a new sequence with stories inserted at position
+func insertStories(stories []*model.Story, position int, inserted []*model.Story) []*model.Story {
+	result := make([]*model.Story, 0, len(stories)+len(inserted))
+	result = append(result, stories[:position]...)
+	result = append(result, inserted...)
+	result = append(result, stories[position:]...)
+	return result
+}
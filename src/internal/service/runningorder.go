@@ -0,0 +1,331 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"airshift/openmos/internal/model"
+	"airshift/openmos/internal/repository"
+	"airshift/openmos/internal/xml"
+)
+
+// errStoryInOtherRunningOrder rejects a story whose ID is already used by
+// another running order
+var errStoryInOtherRunningOrder = errors.New("story belongs to another running order")
+
+// syncStories reconciles the stored stories of a running order against the
+// stories of an incoming message. Stories missing from the message are deleted
+// together with their items. Invalid stories are rejected without aborting the
+// others. The stories are returned in running order sequence along with the
+// result of each incoming story.
+func (s *MOSService) syncStories(ctx context.Context, roID string, storyInfos []xml.StoryInfo) ([]*model.Story, []ElementStatus, error) {
+	existing, err := s.storyRepo.ListByRunningOrder(ctx, roID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list stories: %w", err)
+	}
+
+	existingByID := make(map[string]*model.Story, len(existing))
+	for _, story := range existing {
+		existingByID[story.ID] = story
+	}
+
+	incoming := make(map[string]bool, len(storyInfos))
+	for _, storyInfo := range storyInfos {
+		incoming[storyInfo.ID] = true
+	}
+
+	// Delete stories that are no longer part of the running order
+	for _, story := range existing {
+		if !incoming[story.ID] {
+			err = s.deleteStoryTree(ctx, story.ID)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	// Build the new story sequence. A rejected story keeps its stored version.
+	stories := make([]*model.Story, 0, len(storyInfos))
+	results := make([]ElementStatus, 0, len(storyInfos))
+	isNew := make(map[string]bool)
+	seen := make(map[string]bool, len(storyInfos))
+	for _, storyInfo := range storyInfos {
+		failures := validateStoryInfo(storyInfo)
+		if len(failures) == 0 && seen[storyInfo.ID] {
+			failures = []ElementStatus{elementFailed(storyInfo.ID, fmt.Errorf("duplicate story %s", storyInfo.ID))}
+		}
+		if len(failures) > 0 {
+			results = append(results, failures...)
+			if story, ok := existingByID[storyInfo.ID]; ok && !seen[storyInfo.ID] {
+				stories = append(stories, story)
+			}
+			seen[storyInfo.ID] = true
+			continue
+		}
+		seen[storyInfo.ID] = true
+
+		story, created, err := s.buildStory(ctx, roID, existingByID[storyInfo.ID], storyInfo)
+		if errors.Is(err, errStoryInOtherRunningOrder) {
+			results = append(results, elementFailed(storyInfo.ID, err))
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if created {
+			isNew[story.ID] = true
+		}
+
+		stories = append(stories, story)
+		results = append(results, elementOK(story.ID))
+	}
+
+	linkStories(stories)
+
+	// Persist the stories
+	for _, story := range stories {
+		if isNew[story.ID] {
+			_, err = s.storyRepo.Create(ctx, story)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to create story: %w", err)
+			}
+			continue
+		}
+
+		err = s.storyRepo.Update(ctx, story)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to update story: %w", err)
+		}
+	}
+
+	return stories, results, nil
+}
+
+// validateStoryInfo checks an incoming story and its items. It returns a
+// failed result for each rejected element, or none when the story is valid.
+func validateStoryInfo(storyInfo xml.StoryInfo) []ElementStatus {
+	if storyInfo.ID == "" {
+		return []ElementStatus{elementFailed("", fmt.Errorf("missing storyID"))}
+	}
+
+	var failures []ElementStatus
+	storyFailed := !validDuration(storyInfo.Duration)
+	if storyFailed {
+		failures = append(failures, elementFailed(storyInfo.ID, fmt.Errorf("invalid storyDur %q for story %s", storyInfo.Duration, storyInfo.ID)))
+	}
+
+	itemIDs := make(map[string]bool, len(storyInfo.Items))
+	for _, itemInfo := range storyInfo.Items {
+		var err error
+		switch {
+		case itemInfo.ID == "":
+			err = fmt.Errorf("missing itemID in story %s", storyInfo.ID)
+		case itemIDs[itemInfo.ID]:
+			err = fmt.Errorf("duplicate item %s in story %s", itemInfo.ID, storyInfo.ID)
+		case !validDuration(itemInfo.Duration):
+			err = fmt.Errorf("invalid itemDur %q for item %s", itemInfo.Duration, itemInfo.ID)
+		}
+		itemIDs[itemInfo.ID] = true
+
+		if err != nil {
+			failure := itemFailed(storyInfo.ID, itemInfo.ID, err)
+			failure.ObjID = itemInfo.ObjectID
+			failure.ItemChannel = itemInfo.Channel
+			failures = append(failures, failure)
+		}
+	}
+
+	// A bad item rejects the whole story, so tell the NCS its other items
+	// were dropped as well
+	if !storyFailed && len(failures) > 0 {
+		failures = append(failures, elementFailed(storyInfo.ID, fmt.Errorf("story %s rejected: %d invalid item(s)", storyInfo.ID, len(failures))))
+	}
+
+	return failures
+}
+
+// validDuration reports whether an optional duration is a non-negative number
+func validDuration(value string) bool {
+	if value == "" {
+		return true
+	}
+	duration, err := strconv.Atoi(value)
+	return err == nil && duration >= 0
+}
+
+// buildStory applies an incoming story to its stored counterpart and
+// reconciles its items. When existing is nil the story is looked up by ID, and
+// a new story is returned when none is stored. A stored story of another
+// running order is rejected rather than moved. The story itself is not persisted.
+func (s *MOSService) buildStory(ctx context.Context, roID string, existing *model.Story, storyInfo xml.StoryInfo) (*model.Story, bool, error) {
+	story := existing
+	isNew := false
+	if story == nil {
+		var err error
+		story, err = s.storyRepo.Get(ctx, storyInfo.ID)
+		switch {
+		case err == nil && story.RunningOrderID != roID:
+			return nil, false, fmt.Errorf("%w: story %s is in running order %s", errStoryInOtherRunningOrder, story.ID, story.RunningOrderID)
+		case errors.Is(err, repository.ErrNotFound):
+			story = &model.Story{
+				ID:        storyInfo.ID,
+				Status:    model.StatusPending,
+				CreatedAt: time.Now(),
+			}
+			isNew = true
+		case err != nil:
+			return nil, false, fmt.Errorf("failed to get story %s: %w", storyInfo.ID, err)
+		}
+	}
+
+	story.RunningOrderID = roID
+	story.Slug = storyInfo.Slug
+	story.Number = storyInfo.Number
+	story.UpdatedAt = time.Now()
+
+	items, err := s.syncItems(ctx, story.ID, storyInfo.Items)
+	if err != nil {
+		return nil, false, err
+	}
+	story.Duration = storyDuration(storyInfo.Duration, items)
+
+	return story, isNew, nil
+}
+
+// syncItems reconciles the stored items of a story against the items of an
+// incoming message. The items are returned in story sequence.
+func (s *MOSService) syncItems(ctx context.Context, storyID string, itemInfos []xml.ItemInfo) ([]*model.Item, error) {
+	existing, err := s.itemRepo.ListByStory(ctx, storyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list items for story %s: %w", storyID, err)
+	}
+
+	existingByID := make(map[string]*model.Item, len(existing))
+	for _, item := range existing {
+		existingByID[item.ID] = item
+	}
+
+	incoming := make(map[string]bool, len(itemInfos))
+	for _, itemInfo := range itemInfos {
+		incoming[itemKey(storyID, itemInfo.ID)] = true
+	}
+
+	// Delete items that are no longer part of the story
+	for _, item := range existing {
+		if !incoming[item.ID] {
+			err = s.itemRepo.Delete(ctx, item.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to delete item %s: %w", item.ID, err)
+			}
+		}
+	}
+
+	items := make([]*model.Item, 0, len(itemInfos))
+	for i, itemInfo := range itemInfos {
+		item, ok := existingByID[itemKey(storyID, itemInfo.ID)]
+		if !ok {
+			item = &model.Item{
+				ID:      itemKey(storyID, itemInfo.ID),
+				ItemID:  itemInfo.ID,
+				StoryID: storyID,
+				Status:  model.StatusPending,
+			}
+		}
+
+		applyItemInfo(item, itemInfo)
+		item.Order = i + 1
+
+		if ok {
+			err = s.itemRepo.Update(ctx, item)
+			if err != nil {
+				return nil, fmt.Errorf("failed to update item: %w", err)
+			}
+		} else {
+			_, err = s.itemRepo.Create(ctx, item)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create item: %w", err)
+			}
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// applyItemInfo copies the fields of an incoming item onto a stored item
+func applyItemInfo(item *model.Item, itemInfo xml.ItemInfo) {
+	item.Slug = itemInfo.Slug
+	item.ObjectID = itemInfo.ObjectID
+	item.MosID = itemInfo.MosID
+	item.Channel = itemInfo.Channel
+	item.Duration = 0
+	if itemInfo.Duration != "" {
+		if duration, err := strconv.Atoi(itemInfo.Duration); err == nil {
+			item.Duration = duration
+		}
+	}
+}
+
+// itemKey returns the storage ID of an item. MOS item IDs are only unique
+// within their story.
+func itemKey(storyID, itemID string) string {
+	return fmt.Sprintf("%s_%s", storyID, itemID)
+}
+
+// storyDuration returns the duration of a story: the sum of its item
+// durations, or the declared duration for stories without items
+func storyDuration(declared string, items []*model.Item) int {
+	if len(items) > 0 {
+		duration := 0
+		for _, item := range items {
+			duration += item.Duration
+		}
+		return duration
+	}
+
+	if declared != "" {
+		if duration, err := strconv.Atoi(declared); err == nil {
+			return duration
+		}
+	}
+
+	return 0
+}
+
+// linkStories sets the order and the linked-list pointers of stories in sequence
+func linkStories(stories []*model.Story) {
+	for i, story := range stories {
+		story.Order = i + 1
+		story.PreviousID = ""
+		story.NextID = ""
+
+		if i > 0 {
+			story.PreviousID = stories[i-1].ID
+		}
+		if i < len(stories)-1 {
+			story.NextID = stories[i+1].ID
+		}
+	}
+}
+
+// summarizeRunningOrder updates the first and last story pointers and the
+// total duration of a running order from its stories
+func summarizeRunningOrder(ro *model.RunningOrder, stories []*model.Story) {
+	ro.FirstStoryID = ""
+	ro.LastStoryID = ""
+	ro.Duration = 0
+
+	if len(stories) == 0 {
+		return
+	}
+
+	ro.FirstStoryID = stories[0].ID
+	ro.LastStoryID = stories[len(stories)-1].ID
+	for _, story := range stories {
+		ro.Duration += story.Duration
+	}
+}
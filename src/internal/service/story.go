@@ -83,10 +83,7 @@ func (s *MOSService) createNewStory(ctx context.Context, storySend xml.ROStorySe
 	story.Order = len(stories) + 1
 
 	// Process story body
-	err = s.processStoryBody(ctx, story, &storySend.StoryBody)
-	if err != nil {
-		return fmt.Errorf("failed to process story body: %w", err)
-	}
+	storyItems := processStoryBody(story, storySend)
 
 	// Create the story
 	_, err = s.storyRepo.Create(ctx, story)
@@ -94,6 +91,11 @@ func (s *MOSService) createNewStory(ctx context.Context, storySend xml.ROStorySe
 		return fmt.Errorf("failed to create story: %w", err)
 	}
 
+	err = s.saveStoryItems(ctx, story, storyItems)
+	if err != nil {
+		return err
+	}
+
 	// Publish event after successful creation
 	if s.eventBus != nil {
 		s.eventBus.Publish(events.Event{
@@ -115,16 +117,17 @@ func (s *MOSService) updateStory(ctx context.Context, storySend xml.ROStorySend)
 		return fmt.Errorf("story not found: %w", err)
 	}
 
-	// Update story fields
-	story.Slug = storySend.StorySlug
-	story.Number = storySend.StoryNum
+	// Update story fields, keeping those the message leaves out
+	if storySend.StorySlug != "" {
+		story.Slug = storySend.StorySlug
+	}
+	if storySend.StoryNum != "" {
+		story.Number = storySend.StoryNum
+	}
 	story.UpdatedAt = time.Now()
 
 	// Process story body
-	err = s.processStoryBody(ctx, story, &storySend.StoryBody)
-	if err != nil {
-		return fmt.Errorf("failed to process story body: %w", err)
-	}
+	storyItems := processStoryBody(story, storySend)
 
 	// Update the story
 	err = s.storyRepo.Update(ctx, story)
@@ -132,6 +135,11 @@ func (s *MOSService) updateStory(ctx context.Context, storySend xml.ROStorySend)
 		return fmt.Errorf("failed to update story: %w", err)
 	}
 
+	err = s.saveStoryItems(ctx, story, storyItems)
+	if err != nil {
+		return err
+	}
+
 	// Publish event after successful update
 	if s.eventBus != nil {
 		s.eventBus.Publish(events.Event{
@@ -158,35 +166,184 @@ func (s *MOSService) replaceStory(ctx context.Context, storySend xml.ROStorySend
 	return s.createNewStory(ctx, storySend)
 }
 
-// processStoryBody processes the story body and extracts items
-func (s *MOSService) processStoryBody(ctx context.Context, story *model.Story, storyBody *xml.StoryBody) error {
-	// Find all items in the story body
-	var items []*model.Item
-
-	// Iterate through paragraphs
-	for i, paragraph := range storyBody.Paragraphs {
-		// Process each story item
-		for j, storyItem := range paragraph.Items {
-			// Create a new item
-			item := &model.Item{
-				ID:        fmt.Sprintf("%s_I%d_%d", story.ID, i, j),
-				StoryID:   story.ID,
-				Slug:      storyItem.ItemSlug,
-				ObjectID:  storyItem.ObjID,
-				Duration:  storyItem.ItemEdDur,
-				Status:    model.StatusPending,
-				Order:     j + 1,
-				CreatedAt: time.Now(),
-				UpdatedAt: time.Now(),
+// StoreStory stores a story body sent with roStorySend, creating the story
+// when the running order does not have it yet
+func (s *MOSService) StoreStory(ctx context.Context, storySend xml.ROStorySend) error {
+	if storySend.StoryID == "" {
+		return fmt.Errorf("missing storyID")
+	}
+
+	story, err := s.storyRepo.Get(ctx, storySend.StoryID)
+	if err != nil {
+		return s.createNewStory(ctx, storySend)
+	}
+	if story.RunningOrderID != storySend.ROID {
+		return fmt.Errorf("story %s not found in running order %s", storySend.StoryID, storySend.ROID)
+	}
+
+	return s.updateStory(ctx, storySend)
+}
+
+// GetStory retrieves a story with its script
+func (s *MOSService) GetStory(ctx context.Context, storyID string) (*model.Story, error) {
+	return s.storyRepo.Get(ctx, storyID)
+}
+
+// GetStoryScript returns the text of a story read by the presenter, one line
+// per paragraph. Producer instructions and items are left out.
+func (s *MOSService) GetStoryScript(ctx context.Context, storyID string) (string, error) {
+	story, err := s.storyRepo.Get(ctx, storyID)
+	if err != nil {
+		return "", err
+	}
+
+	var lines []string
+	for _, paragraph := range story.Body {
+		var texts []string
+		for _, element := range paragraph.Elements {
+			if element.Type == model.StoryText {
+				texts = append(texts, element.Text)
 			}
+		}
+		if len(texts) > 0 {
+			lines = append(lines, strings.Join(texts, " "))
+		}
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// storyElementTypes maps story body element types to their stored form
+var storyElementTypes = map[string]string{
+	xml.StoryElementText:        model.StoryText,
+	xml.StoryElementPI:          model.StoryInstruction,
+	xml.StoryElementPresenter:   model.StoryPresenter,
+	xml.StoryElementPresenterRR: model.StoryReadRate,
+	xml.StoryElementItem:        model.StoryItemRef,
+}
+
+// processStoryBody stores the script of a story body on the story and returns
+// the items embedded in it. The first presenter and read rate of the script
+// become those of the story.
+func processStoryBody(story *model.Story, storySend xml.ROStorySend) []xml.StoryItem {
+	story.Body = nil
+	story.Presenter = ""
+	story.PresenterRR = ""
 
-			items = append(items, item)
+	var items []xml.StoryItem
+	for _, paragraph := range storySend.StoryBody.Paragraphs {
+		var stored model.StoryParagraph
+		for _, element := range paragraph.Elements {
+			storedElement := model.StoryElement{
+				Type: storyElementTypes[element.Type],
+				Text: element.Text,
+			}
+
+			switch element.Type {
+			case xml.StoryElementItem:
+				items = append(items, *element.Item)
+				storedElement.ItemID = element.Item.ItemID
+			case xml.StoryElementPresenter:
+				if story.Presenter == "" {
+					story.Presenter = element.Text
+				}
+			case xml.StoryElementPresenterRR:
+				if story.PresenterRR == "" {
+					story.PresenterRR = element.Text
+				}
+			}
+
+			stored.Elements = append(stored.Elements, storedElement)
 		}
+		story.Body = append(story.Body, stored)
+	}
+
+	// Merge external metadata, keyed by schema
+	if len(storySend.ExternalMeta) > 0 && story.Metadata == nil {
+		story.Metadata = make(map[string]string)
+	}
+	for _, external := range storySend.ExternalMeta {
+		story.Metadata[external.MosSchema] = external.MosPayload.Content
+	}
+
+	logger.Infof("Found %d paragraphs and %d items in story %s", len(story.Body), len(items), story.ID)
+
+	return items
+}
+
+// saveStoryItems creates or updates the items embedded in a story body. New
+// items are added after the existing items of the story.
+func (s *MOSService) saveStoryItems(ctx context.Context, story *model.Story, storyItems []xml.StoryItem) error {
+	if len(storyItems) == 0 {
+		return nil
 	}
 
-	// Handle item creation/update (will be implemented in a later step)
-	// For now, just log what we found
-	logger.Infof("Found %d items in story %s", len(items), story.ID)
+	existing, err := s.itemRepo.ListByStory(ctx, story.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list items for story %s: %w", story.ID, err)
+	}
+
+	existingByID := make(map[string]*model.Item, len(existing))
+	for _, item := range existing {
+		existingByID[item.ID] = item
+	}
+
+	order := len(existing)
+	for _, storyItem := range storyItems {
+		if storyItem.ItemID == "" {
+			continue
+		}
+
+		item, ok := existingByID[itemKey(story.ID, storyItem.ItemID)]
+		if !ok {
+			order++
+			item = &model.Item{
+				ID:       itemKey(story.ID, storyItem.ItemID),
+				ItemID:   storyItem.ItemID,
+				StoryID:  story.ID,
+				Duration: storyItem.ItemEdDur,
+				Status:   model.StatusPending,
+				Order:    order,
+			}
+			existingByID[item.ID] = item
+		}
+
+		applyStoryItem(item, storyItem)
+
+		if ok {
+			err = s.itemRepo.Update(ctx, item)
+			if err != nil {
+				return fmt.Errorf("failed to update item: %w", err)
+			}
+		} else {
+			_, err = s.itemRepo.Create(ctx, item)
+			if err != nil {
+				return fmt.Errorf("failed to create item: %w", err)
+			}
+		}
+	}
 
 	return nil
 }
+
+// applyStoryItem copies the fields of an item embedded in a story body onto a
+// stored item
+func applyStoryItem(item *model.Item, storyItem xml.StoryItem) {
+	if storyItem.ItemSlug != "" {
+		item.Slug = storyItem.ItemSlug
+	}
+	if storyItem.ItemChannel != "" {
+		item.Channel = storyItem.ItemChannel
+	}
+	item.ObjectID = storyItem.ObjID
+	item.MosID = storyItem.MosID
+	item.EditorialDuration = storyItem.ItemEdDur
+
+	// Merge external metadata, keyed by schema
+	if len(storyItem.ExternalMeta) > 0 && item.Metadata == nil {
+		item.Metadata = make(map[string]string)
+	}
+	for _, external := range storyItem.ExternalMeta {
+		item.Metadata[external.MosSchema] = external.MosPayload.Content
+	}
+}
@@ -0,0 +1,61 @@
+package xml
+
+import (
+	"encoding/xml"
+)
+
+// ROCtrl represents a Profile 5 control command for a running order, story or item.
+// The command is sent as <command> or, by older devices, as <roCtrlCmd>, with an
+// optional <roCtrlTime> at which to execute it.
+// Format: <roCtrl><roID/><storyID/><itemID/><command/></roCtrl>
+type ROCtrl struct {
+	XMLName      xml.Name              `xml:"roCtrl"`
+	RequestID    string                `xml:"requestID,attr,omitempty"`
+	Timestamp    string                `xml:"timestamp,attr,omitempty"`
+	Source       string                `xml:"source,attr,omitempty"`
+	ROID         string                `xml:"roID"`
+	StoryID      string                `xml:"storyID"`
+	ItemID       string                `xml:"itemID"`
+	Command      string                `xml:"command,omitempty"`
+	CtrlCmd      string                `xml:"roCtrlCmd,omitempty"`
+	CtrlTime     string                `xml:"roCtrlTime,omitempty"`
+	ExternalMeta []MosExternalMetadata `xml:"mosExternalMetadata,omitempty"`
+
+	MOSHeader `xml:"-"`
+}
+
+// GetMessageType returns the type of the message
+func (r ROCtrl) GetMessageType() string {
+	return "roCtrl"
+}
+
+// ControlCommand returns the command, whichever element carried it
+func (r ROCtrl) ControlCommand() string {
+	if r.Command != "" {
+		return r.Command
+	}
+	return r.CtrlCmd
+}
+
+// ROItemCue represents an NCS cueing an item for playout
+// Format: <roItemCue><mosID/><roID/><storyID/><itemID/><roEventType/><roEventTime/></roItemCue>
+type ROItemCue struct {
+	XMLName      xml.Name              `xml:"roItemCue"`
+	RequestID    string                `xml:"requestID,attr,omitempty"`
+	Timestamp    string                `xml:"timestamp,attr,omitempty"`
+	Source       string                `xml:"source,attr,omitempty"`
+	MosID        string                `xml:"mosID"`
+	ROID         string                `xml:"roID"`
+	StoryID      string                `xml:"storyID"`
+	ItemID       string                `xml:"itemID"`
+	EventType    string                `xml:"roEventType"`
+	EventTime    string                `xml:"roEventTime"`
+	ExternalMeta []MosExternalMetadata `xml:"mosExternalMetadata,omitempty"`
+
+	MOSHeader `xml:"-"`
+}
+
+// GetMessageType returns the type of the message
+func (r ROItemCue) GetMessageType() string {
+	return "roItemCue"
+}
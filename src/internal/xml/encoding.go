@@ -0,0 +1,206 @@
+package xml
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Encoding identifies the wire encoding used by a MOS peer
+type Encoding string
+
+const (
+	// EncodingAuto detects the encoding from the first bytes received
+	EncodingAuto Encoding = "auto"
+
+	// EncodingUTF8 is plain UTF-8
+	EncodingUTF8 Encoding = "utf-8"
+
+	// EncodingUTF16BE is UCS-2 / UTF-16 big-endian as required by the MOS specification
+	EncodingUTF16BE Encoding = "utf-16be"
+
+	// EncodingUTF16LE is UTF-16 little-endian, sent by some Windows based devices
+	EncodingUTF16LE Encoding = "utf-16le"
+)
+
+// byteOrderMark is the Unicode BOM code point
+const byteOrderMark = '\uFEFF'
+
+// ParseEncoding converts a configured encoding name to an Encoding
+func ParseEncoding(name string) (Encoding, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "auto":
+		return EncodingAuto, nil
+	case "utf-8", "utf8":
+		return EncodingUTF8, nil
+	case "ucs-2", "ucs2", "utf-16", "utf16", "utf-16be", "utf16be":
+		return EncodingUTF16BE, nil
+	case "utf-16le", "utf16le":
+		return EncodingUTF16LE, nil
+	default:
+		return "", fmt.Errorf("unsupported encoding: %s", name)
+	}
+}
+
+// IsUTF16 reports whether the encoding is one of the UTF-16 variants
+func (e Encoding) IsUTF16() bool {
+	return e == EncodingUTF16BE || e == EncodingUTF16LE
+}
+
+// DetectEncoding determines the wire encoding from the first bytes of a stream.
+// It returns the encoding, the length of the BOM to skip, and false when more
+// data is needed to decide.
+func DetectEncoding(data []byte) (Encoding, int, bool) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		return EncodingUTF16BE, 2, true
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		return EncodingUTF16LE, 2, true
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return EncodingUTF8, 3, true
+	case len(data) < 2:
+		return "", 0, false
+	case data[0] == 0xEF && len(data) < 3:
+		// Possibly a truncated UTF-8 BOM
+		return "", 0, false
+	case data[0] == 0x00 && data[1] != 0x00:
+		// 0x00 0x3C is '<' in UTF-16BE
+		return EncodingUTF16BE, 0, true
+	case data[0] != 0x00 && data[1] == 0x00:
+		// 0x3C 0x00 is '<' in UTF-16LE
+		return EncodingUTF16LE, 0, true
+	default:
+		return EncodingUTF8, 0, true
+	}
+}
+
+// WireDecoder converts bytes received from a peer into UTF-8 for the parser.
+// It keeps partial code units between reads so data may be split anywhere.
+type WireDecoder struct {
+	encoding   Encoding
+	encodingMu sync.RWMutex
+	pending    []byte
+}
+
+// NewWireDecoder creates a decoder for the given encoding, or one that
+// detects the encoding from the first bytes when encoding is EncodingAuto
+func NewWireDecoder(encoding Encoding) *WireDecoder {
+	return &WireDecoder{
+		encoding: encoding,
+	}
+}
+
+// Encoding returns the peer's encoding, or EncodingAuto until it has been detected
+func (d *WireDecoder) Encoding() Encoding {
+	d.encodingMu.RLock()
+	defer d.encodingMu.RUnlock()
+	return d.encoding
+}
+
+// Outbound returns the encoding of the messages sent to the peer. Until the
+// peer's encoding has been detected, it is UTF-16BE, the MOS default.
+func (d *WireDecoder) Outbound() Encoding {
+	encoding := d.Encoding()
+	if encoding == EncodingAuto || encoding == "" {
+		return EncodingUTF16BE
+	}
+	return encoding
+}
+
+// Decode converts a chunk of wire data to UTF-8
+func (d *WireDecoder) Decode(data []byte) []byte {
+	d.pending = append(d.pending, data...)
+
+	encoding := d.Encoding()
+	if encoding == EncodingAuto || encoding == "" {
+		detected, bomLength, ok := DetectEncoding(d.pending)
+		if !ok {
+			return nil
+		}
+		d.encodingMu.Lock()
+		d.encoding = detected
+		d.encodingMu.Unlock()
+
+		encoding = detected
+		d.pending = d.pending[bomLength:]
+	}
+
+	if !encoding.IsUTF16() {
+		result := d.pending
+		d.pending = nil
+		return result
+	}
+
+	var order binary.ByteOrder = binary.BigEndian
+	if encoding == EncodingUTF16LE {
+		order = binary.LittleEndian
+	}
+
+	// Only decode complete code units and complete surrogate pairs
+	units := make([]uint16, 0, len(d.pending)/2)
+	for i := 0; i+1 < len(d.pending); i += 2 {
+		units = append(units, order.Uint16(d.pending[i:]))
+	}
+	consumed := len(units) * 2
+	if len(units) > 0 && utf16.IsSurrogate(rune(units[len(units)-1])) && units[len(units)-1] < 0xDC00 {
+		units = units[:len(units)-1]
+		consumed -= 2
+	}
+	d.pending = append([]byte(nil), d.pending[consumed:]...)
+
+	result := make([]byte, 0, len(units))
+	for _, r := range utf16.Decode(units) {
+		if r == byteOrderMark {
+			continue
+		}
+		result = utf8.AppendRune(result, r)
+	}
+
+	return result
+}
+
+// EncodeWire converts a UTF-8 message to the given wire encoding. The encoding
+// named in a leading XML declaration is rewritten to match.
+func EncodeWire(data []byte, encoding Encoding) []byte {
+	if !encoding.IsUTF16() {
+		return data
+	}
+
+	if bytes.HasPrefix(data, []byte("<?xml")) {
+		if declarationEnd := bytes.Index(data, []byte("?>")); declarationEnd != -1 {
+			declaration := bytes.Replace(data[:declarationEnd], []byte(`encoding="UTF-8"`), []byte(`encoding="UTF-16"`), 1)
+			data = append(declaration, data[declarationEnd:]...)
+		}
+	}
+
+	var order binary.ByteOrder = binary.BigEndian
+	if encoding == EncodingUTF16LE {
+		order = binary.LittleEndian
+	}
+
+	units := utf16.Encode([]rune(string(data)))
+	result := make([]byte, len(units)*2)
+	for i, unit := range units {
+		order.PutUint16(result[i*2:], unit)
+	}
+
+	return result
+}
+
+// charsetReader accepts UTF-16 declarations on data that has already been
+// converted to UTF-8 by a WireDecoder
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	encoding, err := ParseEncoding(charset)
+	if err != nil {
+		return nil, err
+	}
+	if encoding == EncodingAuto {
+		return nil, fmt.Errorf("unsupported encoding: %s", charset)
+	}
+	return input, nil
+}
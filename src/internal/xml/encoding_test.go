@@ -0,0 +1,119 @@
+package xml
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDetectEncoding(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      []byte
+		encoding  Encoding
+		bomLength int
+		ok        bool
+	}{
+		{"utf-16be bom", []byte{0xFE, 0xFF, 0x00, '<'}, EncodingUTF16BE, 2, true},
+		{"utf-16le bom", []byte{0xFF, 0xFE, '<', 0x00}, EncodingUTF16LE, 2, true},
+		{"utf-8 bom", []byte{0xEF, 0xBB, 0xBF, '<'}, EncodingUTF8, 3, true},
+		{"utf-16be without bom", []byte{0x00, '<', 0x00, 'm'}, EncodingUTF16BE, 0, true},
+		{"utf-16le without bom", []byte{'<', 0x00, 'm', 0x00}, EncodingUTF16LE, 0, true},
+		{"utf-8 without bom", []byte("<mos>"), EncodingUTF8, 0, true},
+		{"single byte", []byte{0x00}, "", 0, false},
+		{"truncated utf-8 bom", []byte{0xEF, 0xBB}, "", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoding, bomLength, ok := DetectEncoding(tt.data)
+			if encoding != tt.encoding || bomLength != tt.bomLength || ok != tt.ok {
+				t.Errorf("DetectEncoding() = %q, %d, %v; want %q, %d, %v",
+					encoding, bomLength, ok, tt.encoding, tt.bomLength, tt.ok)
+			}
+		})
+	}
+}
+
+func TestWireDecoderDetectsEncoding(t *testing.T) {
+	const message = "<mos><mosID>openmos</mosID><heartbeat/></mos>"
+
+	tests := []struct {
+		name     string
+		wire     []byte
+		encoding Encoding
+	}{
+		{"utf-8", []byte(message), EncodingUTF8},
+		{"utf-16be with bom", append([]byte{0xFE, 0xFF}, EncodeWire([]byte(message), EncodingUTF16BE)...), EncodingUTF16BE},
+		{"utf-16be", EncodeWire([]byte(message), EncodingUTF16BE), EncodingUTF16BE},
+		{"utf-16le with bom", append([]byte{0xFF, 0xFE}, EncodeWire([]byte(message), EncodingUTF16LE)...), EncodingUTF16LE},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder := NewWireDecoder(EncodingAuto)
+
+			// Feed the data a byte at a time to split code units and the BOM
+			var decoded []byte
+			for i := range tt.wire {
+				decoded = append(decoded, decoder.Decode(tt.wire[i:i+1])...)
+			}
+
+			if string(decoded) != message {
+				t.Errorf("decoded %q, want %q", decoded, message)
+			}
+			if decoder.Encoding() != tt.encoding {
+				t.Errorf("detected %q, want %q", decoder.Encoding(), tt.encoding)
+			}
+		})
+	}
+}
+
+func TestWireDecoderSurrogatePairs(t *testing.T) {
+	// U+1F3AC is encoded as the surrogate pair D83C DFAC
+	const message = "<roSlug>Take \U0001F3AC one</roSlug>"
+
+	for _, encoding := range []Encoding{EncodingUTF16BE, EncodingUTF16LE} {
+		wire := EncodeWire([]byte(message), encoding)
+		pair := bytes.Index(wire, EncodeWire([]byte("\U0001F3AC"), encoding))
+		if pair == -1 {
+			t.Fatalf("%s: surrogate pair not found in encoded message", encoding)
+		}
+
+		// Split between the two halves of the pair and inside each half
+		for _, split := range []int{pair + 1, pair + 2, pair + 3} {
+			decoder := NewWireDecoder(encoding)
+			decoded := decoder.Decode(wire[:split])
+			decoded = append(decoded, decoder.Decode(wire[split:])...)
+
+			if string(decoded) != message {
+				t.Errorf("%s split at %d: decoded %q, want %q", encoding, split, decoded, message)
+			}
+		}
+	}
+}
+
+func TestWireDecoderOutbound(t *testing.T) {
+	decoder := NewWireDecoder(EncodingAuto)
+	if got := decoder.Outbound(); got != EncodingUTF16BE {
+		t.Errorf("Outbound() before detection = %q, want %q", got, EncodingUTF16BE)
+	}
+
+	decoder.Decode([]byte("<mos>"))
+	if got := decoder.Outbound(); got != EncodingUTF8 {
+		t.Errorf("Outbound() after detecting UTF-8 = %q, want %q", got, EncodingUTF8)
+	}
+
+	if got := NewWireDecoder(EncodingUTF16LE).Outbound(); got != EncodingUTF16LE {
+		t.Errorf("Outbound() with configured encoding = %q, want %q", got, EncodingUTF16LE)
+	}
+}
+
+func TestEncodeWireRewritesDeclaration(t *testing.T) {
+	wire := EncodeWire([]byte(`<?xml version="1.0" encoding="UTF-8"?><mos/>`), EncodingUTF16BE)
+
+	decoded := NewWireDecoder(EncodingUTF16BE).Decode(wire)
+	want := `<?xml version="1.0" encoding="UTF-16"?><mos/>`
+	if string(decoded) != want {
+		t.Errorf("decoded %q, want %q", decoded, want)
+	}
+}
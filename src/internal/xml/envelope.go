@@ -0,0 +1,135 @@
+package xml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// envelopeTag is the root element wrapping every MOS message
+const envelopeTag = "mos"
+
+// MOSHeader holds the envelope fields sent with every MOS message
+// Format: <mos><mosID/><ncsID/><messageID/>...</mos>
+type MOSHeader struct {
+	MosID     string
+	NcsID     string
+	MessageID string
+}
+
+// GetHeader returns the envelope header of the message
+func (h MOSHeader) GetHeader() MOSHeader {
+	return h
+}
+
+// SetHeader sets the envelope header of the message
+func (h *MOSHeader) SetHeader(header MOSHeader) {
+	*h = header
+}
+
+// Reply returns the header for a reply to this message sent by the given MOS
+func (h MOSHeader) Reply(mosID string) MOSHeader {
+	return MOSHeader{
+		MosID:     mosID,
+		NcsID:     h.NcsID,
+		MessageID: h.MessageID,
+	}
+}
+
+// WithHeader returns a copy of the message carrying the given envelope header
+func WithHeader(message MOSMessage, header MOSHeader) MOSMessage {
+	value := reflect.New(reflect.TypeOf(message))
+	value.Elem().Set(reflect.ValueOf(message))
+	if setter, ok := value.Interface().(interface{ SetHeader(MOSHeader) }); ok {
+		setter.SetHeader(header)
+	}
+	return value.Elem().Interface().(MOSMessage)
+}
+
+// envelope is the wire representation of the <mos> wrapper
+type envelope struct {
+	XMLName   xml.Name `xml:"mos"`
+	MosID     string   `xml:"mosID"`
+	NcsID     string   `xml:"ncsID"`
+	MessageID string   `xml:"messageID"`
+	Message   MOSMessage
+}
+
+// unwrapEnvelope splits a complete message into its header and payload.
+// Messages without a <mos> wrapper are returned as-is with an empty header.
+func unwrapEnvelope(data []byte) (MOSHeader, []byte, error) {
+	var header MOSHeader
+	var payload []byte
+
+	decoder := newDecoder(data)
+
+	// Find the root element
+	var root xml.StartElement
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return header, nil, fmt.Errorf("%w: %v", ErrInvalidXML, err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			root = start
+			break
+		}
+	}
+
+	if root.Name.Local != envelopeTag {
+		return header, data, nil
+	}
+
+	// Read the header fields and the payload element
+	for {
+		offset := decoder.InputOffset()
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return header, nil, ErrIncompleteXML
+		}
+		if err != nil {
+			return header, nil, fmt.Errorf("%w: %v", ErrInvalidXML, err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "mosID":
+				err = decoder.DecodeElement(&header.MosID, &t)
+			case "ncsID":
+				err = decoder.DecodeElement(&header.NcsID, &t)
+			case "messageID":
+				err = decoder.DecodeElement(&header.MessageID, &t)
+			default:
+				err = decoder.Skip()
+				if err == nil && payload == nil {
+					payload = data[offset:decoder.InputOffset()]
+				}
+			}
+			if err != nil {
+				return header, nil, fmt.Errorf("%w: %v", ErrInvalidXML, err)
+			}
+
+		case xml.EndElement:
+			if payload == nil {
+				return header, nil, fmt.Errorf("%w: empty %s envelope", ErrInvalidXML, envelopeTag)
+			}
+			return header, payload, nil
+		}
+	}
+}
+
+// rootName returns the name of the first element in a complete message
+func rootName(data []byte) (string, error) {
+	decoder := newDecoder(data)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrInvalidXML, err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
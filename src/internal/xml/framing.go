@@ -0,0 +1,151 @@
+package xml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// frame locates a complete message inside the parser buffer
+type frame struct {
+	start int // Offset of the root element
+	end   int // Offset just past the root element's end tag
+}
+
+// frameScan is the tokenizer state kept between appends while the first
+// message of the buffer is incomplete, so that each append only tokenizes the
+// data received since the last complete token
+type frameScan struct {
+	offset int // Offset just past the last complete token
+	depth  int // Element depth at offset
+	start  int // Offset of the root element, -1 before it was seen
+}
+
+// nextFrame returns the bounds of the first complete message in the buffer.
+// The buffer is tokenized with encoding/xml, tracking element depth, so that
+// nested and self-closing elements do not end the message early. XML
+// declarations, comments and whitespace before the root element are skipped.
+// ErrIncompleteXML is returned while the root element is still open; the
+// result is kept until the buffer changes.
+func (p *MessageParser) nextFrame() (frame, error) {
+	if p.framed != nil {
+		return *p.framed, nil
+	}
+	if p.scanned == len(p.buffer) {
+		return frame{}, ErrIncompleteXML
+	}
+
+	found, err := p.scan.resume(p.buffer)
+	if errors.Is(err, ErrIncompleteXML) {
+		p.scanned = len(p.buffer)
+		return frame{}, err
+	}
+	if err != nil {
+		return found, err
+	}
+
+	p.framed = &found
+	return found, nil
+}
+
+// resetFrame forgets the framing result after the start of the buffer was consumed
+func (p *MessageParser) resetFrame() {
+	p.framed = nil
+	p.scanned = -1
+	p.scan = frameScan{start: -1}
+}
+
+// resume tokenizes data from the last complete token up to the end of its
+// first root element. Raw tokens are used as the element stack of a decoder
+// started mid-message is unknown; the depth is tracked here instead.
+func (s *frameScan) resume(data []byte) (frame, error) {
+	base := s.offset
+	decoder := newDecoder(data[base:completeLength(data)])
+
+	for {
+		tokenStart := base + int(decoder.InputOffset())
+		token, err := decoder.RawToken()
+		if err != nil {
+			if isTruncated(err) {
+				return frame{}, ErrIncompleteXML
+			}
+			found := frame{start: s.start, end: base + int(decoder.InputOffset())}
+			if found.start == -1 {
+				found.start = tokenStart
+			}
+			return found, fmt.Errorf("%w: %v", ErrInvalidXML, err)
+		}
+
+		// The end of a self-closing element is returned without reading
+		// further input, so the offset never falls between the two tokens
+		tokenEnd := base + int(decoder.InputOffset())
+		switch token.(type) {
+		case xml.StartElement:
+			if s.depth == 0 {
+				s.start = tokenStart
+			}
+			s.depth++
+
+		case xml.EndElement:
+			s.depth--
+			if s.depth == 0 {
+				return frame{start: s.start, end: tokenEnd}, nil
+			}
+		}
+		s.offset = tokenEnd
+	}
+}
+
+// completeLength returns the length of data without a trailing partial UTF-8
+// sequence, which encoding/xml would reject as invalid
+func completeLength(data []byte) int {
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				return i
+			}
+			break
+		}
+	}
+	return len(data)
+}
+
+// isTruncated reports whether a decoding error only means more data is needed
+func isTruncated(err error) bool {
+	if err == io.EOF {
+		return true
+	}
+	var syntaxErr *xml.SyntaxError
+	return errors.As(err, &syntaxErr) && strings.HasPrefix(syntaxErr.Msg, "unexpected EOF")
+}
+
+// resyncOffset returns where parsing resumes after a malformed message that
+// starts at start: the next <mos> envelope, or else the last tag opening in the
+// buffer, which may begin a message that has not fully arrived
+func resyncOffset(data []byte, start int) int {
+	from := start + 1
+	if from >= len(data) {
+		return len(data)
+	}
+
+	for offset := from; offset < len(data); {
+		index := bytes.Index(data[offset:], []byte("<"+envelopeTag))
+		if index == -1 {
+			break
+		}
+		next := offset + index + len(envelopeTag) + 1
+		if next >= len(data) || bytes.IndexByte([]byte("> \t\r\n"), data[next]) != -1 {
+			return offset + index
+		}
+		offset = next
+	}
+
+	if last := bytes.LastIndexByte(data[from:], '<'); last != -1 {
+		return from + last
+	}
+	return len(data)
+}
@@ -0,0 +1,153 @@
+package xml
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// frames feeds data to a parser in chunks of the given size and returns the
+// complete messages it frames, along with the data left in the buffer
+func frames(t *testing.T, data string, chunk int) ([]string, string) {
+	t.Helper()
+
+	parser := NewMessageParser()
+	var found []string
+	for offset := 0; offset < len(data); offset += chunk {
+		end := min(offset+chunk, len(data))
+		parser.AppendData([]byte(data[offset:end]))
+
+		for {
+			frame, err := parser.nextFrame()
+			if errors.Is(err, ErrIncompleteXML) {
+				break
+			}
+			if err != nil {
+				t.Fatalf("nextFrame() error: %v", err)
+			}
+			found = append(found, string(parser.buffer[frame.start:frame.end]))
+			parser.discardMessage()
+		}
+	}
+
+	return found, string(parser.buffer)
+}
+
+func TestFraming(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		frames []string
+		rest   string
+	}{
+		{
+			name:   "nested elements with the root's name",
+			data:   `<mos><mosPayload><mos><mos>inner</mos></mos></mosPayload></mos>`,
+			frames: []string{`<mos><mosPayload><mos><mos>inner</mos></mos></mosPayload></mos>`},
+		},
+		{
+			name:   "self-closing elements",
+			data:   `<mos><roCreate><item/><item /><story><item/></story></roCreate></mos>`,
+			frames: []string{`<mos><roCreate><item/><item /><story><item/></story></roCreate></mos>`},
+		},
+		{
+			name:   "self-closing root",
+			data:   `<mos/>`,
+			frames: []string{`<mos/>`},
+		},
+		{
+			name:   "back-to-back messages",
+			data:   `<mos><heartbeat/></mos><mos><reqMachInfo/></mos>` + "\r\n" + `<mos><roAck/></mos>`,
+			frames: []string{`<mos><heartbeat/></mos>`, `<mos><reqMachInfo/></mos>`, `<mos><roAck/></mos>`},
+		},
+		{
+			name:   "declaration before the root",
+			data:   `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<mos><heartbeat/></mos>`,
+			frames: []string{`<mos><heartbeat/></mos>`},
+		},
+		{
+			name:   "comments around and inside the message",
+			data:   `<!-- before --><mos><!-- </mos> --><heartbeat/></mos>`,
+			frames: []string{`<mos><!-- </mos> --><heartbeat/></mos>`},
+		},
+		{
+			name:   "markup in CDATA and attributes",
+			data:   `<mos><roSlug><![CDATA[</mos>]]></roSlug><item note="a &gt; b"/></mos>`,
+			frames: []string{`<mos><roSlug><![CDATA[</mos>]]></roSlug><item note="a &gt; b"/></mos>`},
+		},
+		{
+			name:   "multi-byte characters",
+			data:   `<mos><roSlug>Météo – 天気 🎬</roSlug></mos>`,
+			frames: []string{`<mos><roSlug>Météo – 天気 🎬</roSlug></mos>`},
+		},
+		{
+			name:   "trailing partial message is kept",
+			data:   `<mos><heartbeat/></mos><mos><roCreate><roID>RO1</ro`,
+			frames: []string{`<mos><heartbeat/></mos>`},
+			rest:   `<mos><roCreate><roID>RO1</ro`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Whole, and split at every possible byte
+			for _, chunk := range []int{len(tt.data), 1, 3, 7} {
+				found, rest := frames(t, tt.data, chunk)
+
+				if strings.Join(found, "|") != strings.Join(tt.frames, "|") {
+					t.Errorf("chunk %d: framed %q, want %q", chunk, found, tt.frames)
+				}
+				if rest != tt.rest {
+					t.Errorf("chunk %d: buffer left %q, want %q", chunk, rest, tt.rest)
+				}
+			}
+		})
+	}
+}
+
+func TestFramingResumesAcrossAppends(t *testing.T) {
+	parser := NewMessageParser()
+	parser.AppendData([]byte(`<mos><roCreate><story><storySlug>One</storySlug>`))
+	if parser.HasCompleteMessage() {
+		t.Fatal("incomplete message reported as complete")
+	}
+	scanned := parser.scan.offset
+
+	parser.AppendData([]byte(`</story><story><item/>`))
+	if parser.HasCompleteMessage() {
+		t.Fatal("incomplete message reported as complete")
+	}
+	if parser.scan.offset <= scanned {
+		t.Errorf("scan did not advance: offset %d after %d", parser.scan.offset, scanned)
+	}
+	if parser.scan.depth != 3 {
+		t.Errorf("depth = %d, want 3", parser.scan.depth)
+	}
+
+	parser.AppendData([]byte(`</story></roCreate></mos><mos>`))
+	if !parser.HasCompleteMessage() {
+		t.Fatal("complete message not detected")
+	}
+	frame, _ := parser.nextFrame()
+	if got := string(parser.buffer[frame.start:frame.end]); !strings.HasSuffix(got, `</roCreate></mos>`) {
+		t.Errorf("framed %q", got)
+	}
+}
+
+func TestFramingMalformedMessage(t *testing.T) {
+	parser := NewMessageParser()
+	parser.AppendData([]byte(`<mos><heartbeat></mos&><mos><reqMachInfo/></mos>`))
+
+	_, _, err := parser.Parse()
+	if !errors.Is(err, ErrInvalidXML) {
+		t.Fatalf("Parse() error = %v, want ErrInvalidXML", err)
+	}
+
+	message, _, err := parser.Parse()
+	if err != nil {
+		t.Fatalf("Parse() after resync error: %v", err)
+	}
+	if message.GetMessageType() != "reqMachInfo" {
+		t.Errorf("parsed %s after resync, want reqMachInfo", message.GetMessageType())
+	}
+}
@@ -5,9 +5,16 @@ import (
 	"fmt"
 )
 
-// GenerateMessage serializes a MOS message to XML
+// GenerateMessage serializes a MOS message to XML wrapped in the <mos> envelope
+// carrying the message header
 func GenerateMessage(message MOSMessage) ([]byte, error) {
-	data, err := xml.Marshal(message)
+	header := message.GetHeader()
+	data, err := xml.Marshal(envelope{
+		MosID:     header.MosID,
+		NcsID:     header.NcsID,
+		MessageID: header.MessageID,
+		Message:   message,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal XML: %w", err)
 	}
@@ -23,6 +30,7 @@ func CreateHeartbeat(source string, requestID string) Heartbeat {
 		RequestID: requestID,
 		Timestamp: Now(),
 		Source:    source,
+		Time:      Now(),
 	}
 }
 
@@ -32,6 +40,7 @@ func CreateHeartbeatResponse(source string, requestID string) Heartbeat {
 		RequestID: requestID,
 		Timestamp: Now(),
 		Source:    source,
+		Time:      Now(),
 	}
 }
 
@@ -46,6 +55,46 @@ func CreateMOSAck(source string, requestID string, status string, description st
 	}
 }
 
+// CreateROAck creates a running order acknowledgment message
+func CreateROAck(source string, requestID string, roID string, status string) ROAck {
+	return ROAck{
+		RequestID: requestID,
+		Timestamp: Now(),
+		Source:    source,
+		ROID:      roID,
+		ROStatus:  status,
+	}
+}
+
+// CreateROAckResults creates a running order acknowledgment carrying the
+// status of each story, item or object touched by the acknowledged message
+func CreateROAckResults(source string, requestID string, roID string, status string, elements []ROAckElement) ROAck {
+	ack := CreateROAck(source, requestID, roID, status)
+	ack.Elements = elements
+	return ack
+}
+
+// CreateROAckElement creates the status entry of a single element in an roAck
+func CreateROAckElement(storyID string, itemID string, objID string, itemChannel string, status string) ROAckElement {
+	return ROAckElement{
+		StoryID:     storyID,
+		ItemID:      itemID,
+		ObjID:       objID,
+		ItemChannel: itemChannel,
+		Status:      status,
+	}
+}
+
+// CreateRODelete creates a running order delete message
+func CreateRODelete(source string, requestID string, roID string) RODelete {
+	return RODelete{
+		RequestID: requestID,
+		Timestamp: Now(),
+		Source:    source,
+		ROID:      roID,
+	}
+}
+
 // CreateRunningOrderList creates a running order list message
 func CreateRunningOrderList(source string, requestID string, items []ROListItem) RunningOrderList {
 	return RunningOrderList{
@@ -75,6 +124,26 @@ func CreateRunningOrderInfo(source string, requestID string, id string, slug str
 	}
 }
 
+// CreateSupportedProfiles creates the supported profiles block for MOS profiles 0-7
+func CreateSupportedProfiles(deviceType string, supported map[int]bool) SupportedProfiles {
+	profiles := make([]MOSProfile, 0, 8)
+	for number := 0; number <= 7; number++ {
+		flag := "NO"
+		if supported[number] {
+			flag = "YES"
+		}
+		profiles = append(profiles, MOSProfile{
+			Number:    number,
+			Supported: flag,
+		})
+	}
+
+	return SupportedProfiles{
+		DeviceType: deviceType,
+		Profiles:   profiles,
+	}
+}
+
 // CreateStoryResponse creates a response to a story creation request
 func CreateStoryResponse(requestID, source, status, description string) ([]byte, error) {
 	ack := MOSAck{
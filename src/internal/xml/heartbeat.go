@@ -84,8 +84,9 @@ func (h *HeartbeatMonitor) LastSeenTime() time.Time {
 }
 
 // CreateHeartbeatResponse creates a response to a heartbeat request
-func (h *HeartbeatMonitor) CreateHeartbeatResponse(requestID string) ([]byte, error) {
-	response := CreateHeartbeatResponse(h.source, requestID)
+func (h *HeartbeatMonitor) CreateHeartbeatResponse(request Heartbeat) ([]byte, error) {
+	response := CreateHeartbeatResponse(h.source, request.RequestID)
+	response.MOSHeader = request.Reply(h.source)
 	return GenerateMessage(response)
 }
 
@@ -95,7 +96,7 @@ func ProcessHeartbeat(heartbeat Heartbeat, monitor *HeartbeatMonitor) ([]byte, e
 	monitor.RecordHeartbeat()
 
 	// Create a response
-	response, err := monitor.CreateHeartbeatResponse(heartbeat.RequestID)
+	response, err := monitor.CreateHeartbeatResponse(heartbeat)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create heartbeat response: %w", err)
 	}
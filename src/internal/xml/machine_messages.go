@@ -0,0 +1,54 @@
+package xml
+
+import (
+	"encoding/xml"
+)
+
+// ReqMachInfo represents a request for machine information
+// Format: <reqMachInfo/>
+type ReqMachInfo struct {
+	XMLName xml.Name `xml:"reqMachInfo"`
+
+	MOSHeader `xml:"-"`
+}
+
+// GetMessageType returns the type of the message
+func (r ReqMachInfo) GetMessageType() string {
+	return "reqMachInfo"
+}
+
+// ListMachInfo represents the machine information response
+type ListMachInfo struct {
+	XMLName           xml.Name          `xml:"listMachInfo"`
+	Manufacturer      string            `xml:"manufacturer"`
+	Model             string            `xml:"model"`
+	HWRev             string            `xml:"hwRev"`
+	SWRev             string            `xml:"swRev"`
+	DOM               string            `xml:"DOM"`
+	SN                string            `xml:"SN"`
+	ID                string            `xml:"ID"`
+	Time              string            `xml:"time"`
+	OpTime            string            `xml:"opTime,omitempty"`
+	MOSRev            string            `xml:"mosRev"`
+	SupportedProfiles SupportedProfiles `xml:"supportedProfiles"`
+
+	MOSHeader `xml:"-"`
+}
+
+// GetMessageType returns the type of the message
+func (l ListMachInfo) GetMessageType() string {
+	return "listMachInfo"
+}
+
+// SupportedProfiles lists the MOS profiles implemented by a device
+type SupportedProfiles struct {
+	DeviceType string       `xml:"deviceType,attr"`
+	Profiles   []MOSProfile `xml:"mosProfile"`
+}
+
+// MOSProfile represents the support flag for a single MOS profile
+// Format: <mosProfile number="0">YES</mosProfile>
+type MOSProfile struct {
+	Number    int    `xml:"number,attr"`
+	Supported string `xml:",chardata"`
+}
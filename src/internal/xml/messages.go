@@ -2,30 +2,40 @@ package xml
 
 import (
 	"encoding/xml"
+	"strings"
 	"time"
 )
 
 // MOSMessage is the base interface for all MOS messages
 type MOSMessage interface {
 	GetMessageType() string
+	GetHeader() MOSHeader
 }
 
 // MosExternalMetadata represents external metadata in MOS messages
 type MosExternalMetadata struct {
-	XMLName    xml.Name `xml:"mosExternalMetadata"`
-	MosScope   string   `xml:"mosScope,omitempty"`
-	MosSchema  string   `xml:"mosSchema"`
-	MosPayload string   `xml:"mosPayload"`
+	XMLName    xml.Name   `xml:"mosExternalMetadata"`
+	MosScope   string     `xml:"mosScope,omitempty"`
+	MosSchema  string     `xml:"mosSchema"`
+	MosPayload MosPayload `xml:"mosPayload"`
+}
+
+// MosPayload holds the raw XML content of a mosPayload element
+type MosPayload struct {
+	Content string `xml:",innerxml"`
 }
 
 // Heartbeat represents a MOS heartbeat message
-// Format: <heartbeat/>
-// or <heartbeat timestamp="timestamp" source="source"/>
+// Format: <heartbeat><time/></heartbeat>
+// or <heartbeat timestamp="timestamp" source="source"><time/></heartbeat>
 type Heartbeat struct {
 	XMLName   xml.Name `xml:"heartbeat"`
 	RequestID string   `xml:"requestID,attr,omitempty"`
 	Timestamp string   `xml:"timestamp,attr,omitempty"`
 	Source    string   `xml:"source,attr,omitempty"`
+	Time      string   `xml:"time"`
+
+	MOSHeader `xml:"-"`
 }
 
 // GetMessageType returns the type of the message
@@ -33,27 +43,32 @@ func (h Heartbeat) GetMessageType() string {
 	return "heartbeat"
 }
 
-// ReqRunningOrderList represents a request for running order list
-// Format: <reqMachInfo/>
+// ReqRunningOrderList represents a request for the list of all running orders
+// Format: <roReqAll/>
 type ReqRunningOrderList struct {
-	XMLName   xml.Name `xml:"roReq"`
+	XMLName   xml.Name `xml:"roReqAll"`
 	RequestID string   `xml:"requestID,attr,omitempty"`
 	Timestamp string   `xml:"timestamp,attr,omitempty"`
 	Source    string   `xml:"source,attr,omitempty"`
+
+	MOSHeader `xml:"-"`
 }
 
 // GetMessageType returns the type of the message
 func (r ReqRunningOrderList) GetMessageType() string {
-	return "roReq"
+	return "roReqAll"
 }
 
 // RunningOrderList represents a response with the list of running orders
+// Format: <roListAll><ro/>*</roListAll>
 type RunningOrderList struct {
-	XMLName      xml.Name     `xml:"roList"`
+	XMLName      xml.Name     `xml:"roListAll"`
 	RequestID    string       `xml:"requestID,attr,omitempty"`
 	Timestamp    string       `xml:"timestamp,attr,omitempty"`
 	Source       string       `xml:"source,attr,omitempty"`
 	RunningOrder []ROListItem `xml:"ro"`
+
+	MOSHeader `xml:"-"`
 }
 
 // ROListItem represents a single running order in a list
@@ -69,21 +84,24 @@ type ROListItem struct {
 
 // GetMessageType returns the type of the message
 func (r RunningOrderList) GetMessageType() string {
-	return "roList"
+	return "roListAll"
 }
 
 // ReqRunningOrder represents a request for a specific running order
+// Format: <roReq><roID/></roReq>
 type ReqRunningOrder struct {
-	XMLName   xml.Name `xml:"roReqAll"`
+	XMLName   xml.Name `xml:"roReq"`
 	RequestID string   `xml:"requestID,attr,omitempty"`
 	Timestamp string   `xml:"timestamp,attr,omitempty"`
 	Source    string   `xml:"source,attr,omitempty"`
 	ROID      string   `xml:"roID"`
+
+	MOSHeader `xml:"-"`
 }
 
 // GetMessageType returns the type of the message
 func (r ReqRunningOrder) GetMessageType() string {
-	return "roReqAll"
+	return "roReq"
 }
 
 // RunningOrderInfo represents a full running order with stories and items
@@ -99,6 +117,33 @@ type RunningOrderInfo struct {
 	StartTime string      `xml:"roTrigger,omitempty"`
 	Duration  string      `xml:"roDur,omitempty"`
 	Stories   []StoryInfo `xml:"story"`
+
+	OnAirOverride
+	MOSHeader `xml:"-"`
+}
+
+// ROReplace represents a full replacement of a running order
+// It carries the same content as roCreate
+type ROReplace struct {
+	XMLName xml.Name `xml:"roReplace"`
+	RunningOrderInfo
+}
+
+// GetMessageType returns the type of the message
+func (r ROReplace) GetMessageType() string {
+	return "roReplace"
+}
+
+// ROList represents a full running order sent in reply to roReq
+// It carries the same content as roCreate
+type ROList struct {
+	XMLName xml.Name `xml:"roList"`
+	RunningOrderInfo
+}
+
+// GetMessageType returns the type of the message
+func (r ROList) GetMessageType() string {
+	return "roList"
 }
 
 // StoryInfo represents a story within a running order
@@ -132,8 +177,12 @@ type MOSAck struct {
 	RequestID         string   `xml:"requestID,attr,omitempty"`
 	Timestamp         string   `xml:"timestamp,attr,omitempty"`
 	Source            string   `xml:"source,attr,omitempty"`
+	ObjID             string   `xml:"objID"`
+	ObjRev            string   `xml:"objRev"`
 	Status            string   `xml:"status"`
-	StatusDescription string   `xml:"statusDescription,omitempty"`
+	StatusDescription string   `xml:"statusDescription"`
+
+	MOSHeader `xml:"-"`
 }
 
 // GetMessageType returns the type of the message
@@ -146,6 +195,8 @@ type NCSAck struct {
 	XMLName           xml.Name `xml:"ncsAck"`
 	Status            string   `xml:"status"`
 	StatusDescription string   `xml:"statusDescription,omitempty"`
+
+	MOSHeader `xml:"-"`
 }
 
 // GetMessageType returns the type of the message
@@ -155,5 +206,23 @@ func (m NCSAck) GetMessageType() string {
 
 // Now returns the current timestamp in MOS format
 func Now() string {
-	return time.Now().Format(time.RFC3339)
+	return FormatTime(time.Now())
+}
+
+// FormatTime formats a time in MOS format
+func FormatTime(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
+
+// ParseTime parses a MOS timestamp, with or without a time zone.
+// MOS separates fractional seconds with a comma, e.g. 2009-04-11T14:22:07,125Z.
+func ParseTime(value string) (time.Time, error) {
+	value = strings.Replace(value, ",", ".", 1)
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return t, nil
+	}
+
+	return time.ParseInLocation("2006-01-02T15:04:05", value, time.Local)
 }
@@ -0,0 +1,280 @@
+package xml
+
+import (
+	"encoding/xml"
+)
+
+// MOSObj describes a MOS object. It is sent on its own, in reply to mosReqObj
+// or as an unsolicited update, and inside mosListAll.
+// Format: <mosObj><objID/><objSlug/>[<mosAbstract/>][<objGroup/>]<objType/><objTB/><objRev/><objDur/><status/><objAir/>[<objPaths/>]<createdBy/><created/><changedBy/><changed/><description/></mosObj>
+type MOSObj struct {
+	XMLName      xml.Name              `xml:"mosObj"`
+	RequestID    string                `xml:"requestID,attr,omitempty"`
+	Timestamp    string                `xml:"timestamp,attr,omitempty"`
+	Source       string                `xml:"source,attr,omitempty"`
+	ObjID        string                `xml:"objID"`
+	ObjSlug      string                `xml:"objSlug"`
+	MosAbstract  string                `xml:"mosAbstract,omitempty"`
+	ObjGroup     string                `xml:"objGroup,omitempty"`
+	ObjType      string                `xml:"objType"`
+	ObjTB        string                `xml:"objTB"`
+	ObjRev       string                `xml:"objRev"`
+	ObjDur       string                `xml:"objDur"`
+	Status       string                `xml:"status"`
+	ObjAir       string                `xml:"objAir"`
+	ObjPaths     *ObjPaths             `xml:"objPaths,omitempty"`
+	CreatedBy    string                `xml:"createdBy"`
+	Created      string                `xml:"created"`
+	ChangedBy    string                `xml:"changedBy"`
+	Changed      string                `xml:"changed"`
+	Description  string                `xml:"description"`
+	ExternalMeta []MosExternalMetadata `xml:"mosExternalMetadata,omitempty"`
+
+	MOSHeader `xml:"-"`
+}
+
+// GetMessageType returns the type of the message
+func (m MOSObj) GetMessageType() string {
+	return "mosObj"
+}
+
+// ObjPaths lists the media, proxy and metadata locations of an object
+type ObjPaths struct {
+	Paths         []ObjPath `xml:"objPath,omitempty"`
+	ProxyPaths    []ObjPath `xml:"objProxyPath,omitempty"`
+	MetadataPaths []ObjPath `xml:"objMetadataPath,omitempty"`
+}
+
+// ObjPath is a single object location with its technical description
+type ObjPath struct {
+	TechDescription string `xml:"techDescription,attr,omitempty"`
+	URL             string `xml:",chardata"`
+}
+
+// MOSReqObj represents a request for a single object
+// Format: <mosReqObj><objID/></mosReqObj>
+type MOSReqObj struct {
+	XMLName   xml.Name `xml:"mosReqObj"`
+	RequestID string   `xml:"requestID,attr,omitempty"`
+	Timestamp string   `xml:"timestamp,attr,omitempty"`
+	Source    string   `xml:"source,attr,omitempty"`
+	ObjID     string   `xml:"objID"`
+
+	MOSHeader `xml:"-"`
+}
+
+// GetMessageType returns the type of the message
+func (m MOSReqObj) GetMessageType() string {
+	return "mosReqObj"
+}
+
+// MOSReqAll represents a request for all objects. A pause of 0 asks for a
+// single mosListAll; a positive pause asks for one mosObj per object with
+// that many seconds between them.
+// Format: <mosReqAll><pause/></mosReqAll>
+type MOSReqAll struct {
+	XMLName   xml.Name `xml:"mosReqAll"`
+	RequestID string   `xml:"requestID,attr,omitempty"`
+	Timestamp string   `xml:"timestamp,attr,omitempty"`
+	Source    string   `xml:"source,attr,omitempty"`
+	Pause     string   `xml:"pause"`
+
+	MOSHeader `xml:"-"`
+}
+
+// GetMessageType returns the type of the message
+func (m MOSReqAll) GetMessageType() string {
+	return "mosReqAll"
+}
+
+// MOSListAll represents the full object catalog
+// Format: <mosListAll><mosObj/>*</mosListAll>
+type MOSListAll struct {
+	XMLName   xml.Name `xml:"mosListAll"`
+	RequestID string   `xml:"requestID,attr,omitempty"`
+	Timestamp string   `xml:"timestamp,attr,omitempty"`
+	Source    string   `xml:"source,attr,omitempty"`
+	Objects   []MOSObj `xml:"mosObj"`
+
+	MOSHeader `xml:"-"`
+}
+
+// GetMessageType returns the type of the message
+func (m MOSListAll) GetMessageType() string {
+	return "mosListAll"
+}
+
+// ObjectFields are the descriptive fields of an object sent by an NCS when
+// it creates or updates an object
+type ObjectFields struct {
+	ObjSlug      string                `xml:"objSlug,omitempty"`
+	MosAbstract  string                `xml:"mosAbstract,omitempty"`
+	ObjGroup     string                `xml:"objGroup,omitempty"`
+	ObjType      string                `xml:"objType,omitempty"`
+	ObjTB        string                `xml:"objTB,omitempty"`
+	ObjDur       string                `xml:"objDur,omitempty"`
+	Time         string                `xml:"time,omitempty"`
+	CreatedBy    string                `xml:"createdBy,omitempty"`
+	ChangedBy    string                `xml:"changedBy,omitempty"`
+	Changed      string                `xml:"changed,omitempty"`
+	Description  string                `xml:"description,omitempty"`
+	ExternalMeta []MosExternalMetadata `xml:"mosExternalMetadata,omitempty"`
+}
+
+// MOSObjCreate represents an NCS asking for a new placeholder object. The
+// MOS assigns the object ID and returns it in a mosAck.
+// Format: <mosObjCreate><objSlug/>[<objGroup/>]<objType/><objTB/>[<objDur/>][<time/>][<createdBy/>][<description/>]</mosObjCreate>
+type MOSObjCreate struct {
+	XMLName   xml.Name `xml:"mosObjCreate"`
+	RequestID string   `xml:"requestID,attr,omitempty"`
+	Timestamp string   `xml:"timestamp,attr,omitempty"`
+	Source    string   `xml:"source,attr,omitempty"`
+	ObjectFields
+
+	MOSHeader `xml:"-"`
+}
+
+// GetMessageType returns the type of the message
+func (m MOSObjCreate) GetMessageType() string {
+	return "mosObjCreate"
+}
+
+// MOSReqObjAction represents an NCS creating, updating or deleting an object.
+// Operation is one of NEW, UPDATE or DELETE; UPDATE and DELETE name the object.
+// Format: <mosReqObjAction operation="" objID="">...</mosReqObjAction>
+type MOSReqObjAction struct {
+	XMLName   xml.Name `xml:"mosReqObjAction"`
+	RequestID string   `xml:"requestID,attr,omitempty"`
+	Timestamp string   `xml:"timestamp,attr,omitempty"`
+	Source    string   `xml:"source,attr,omitempty"`
+	Operation string   `xml:"operation,attr"`
+	ObjID     string   `xml:"objID,attr,omitempty"`
+	ObjectFields
+
+	MOSHeader `xml:"-"`
+}
+
+// GetMessageType returns the type of the message
+func (m MOSReqObjAction) GetMessageType() string {
+	return "mosReqObjAction"
+}
+
+// MOSItemReplace represents a MOS replacing the content of an item it owns
+// inside a running order
+// Format: <mosItemReplace><roID/><storyID/><item/></mosItemReplace>
+type MOSItemReplace struct {
+	XMLName   xml.Name `xml:"mosItemReplace"`
+	RequestID string   `xml:"requestID,attr,omitempty"`
+	Timestamp string   `xml:"timestamp,attr,omitempty"`
+	Source    string   `xml:"source,attr,omitempty"`
+	ROID      string   `xml:"roID"`
+	StoryID   string   `xml:"storyID"`
+	Item      ItemInfo `xml:"item"`
+
+	MOSHeader `xml:"-"`
+}
+
+// GetMessageType returns the type of the message
+func (m MOSItemReplace) GetMessageType() string {
+	return "mosItemReplace"
+}
+
+// MOSReqSearchableSchema represents a request for the schema describing the
+// searchable object fields
+// Format: <mosReqSearchableSchema username=""/>
+type MOSReqSearchableSchema struct {
+	XMLName   xml.Name `xml:"mosReqSearchableSchema"`
+	RequestID string   `xml:"requestID,attr,omitempty"`
+	Timestamp string   `xml:"timestamp,attr,omitempty"`
+	Source    string   `xml:"source,attr,omitempty"`
+	Username  string   `xml:"username,attr,omitempty"`
+
+	MOSHeader `xml:"-"`
+}
+
+// GetMessageType returns the type of the message
+func (m MOSReqSearchableSchema) GetMessageType() string {
+	return "mosReqSearchableSchema"
+}
+
+// MOSListSearchableSchema names the schema describing the searchable object fields
+// Format: <mosListSearchableSchema username=""><mosSchema/></mosListSearchableSchema>
+type MOSListSearchableSchema struct {
+	XMLName   xml.Name `xml:"mosListSearchableSchema"`
+	RequestID string   `xml:"requestID,attr,omitempty"`
+	Timestamp string   `xml:"timestamp,attr,omitempty"`
+	Source    string   `xml:"source,attr,omitempty"`
+	Username  string   `xml:"username,attr,omitempty"`
+	MosSchema string   `xml:"mosSchema"`
+
+	MOSHeader `xml:"-"`
+}
+
+// GetMessageType returns the type of the message
+func (m MOSListSearchableSchema) GetMessageType() string {
+	return "mosListSearchableSchema"
+}
+
+// MOSReqObjList represents a catalog search. Fields within a searchGroup are
+// ANDed; separate searchGroups are ORed.
+// Format: <mosReqObjList username=""><queryID/><listReturnStart/><listReturnEnd/><generalSearch/><mosSchema/><searchGroup/>*</mosReqObjList>
+type MOSReqObjList struct {
+	XMLName         xml.Name      `xml:"mosReqObjList"`
+	RequestID       string        `xml:"requestID,attr,omitempty"`
+	Timestamp       string        `xml:"timestamp,attr,omitempty"`
+	Source          string        `xml:"source,attr,omitempty"`
+	Username        string        `xml:"username,attr,omitempty"`
+	QueryID         string        `xml:"queryID"`
+	ListReturnStart string        `xml:"listReturnStart"`
+	ListReturnEnd   string        `xml:"listReturnEnd"`
+	GeneralSearch   string        `xml:"generalSearch"`
+	MosSchema       string        `xml:"mosSchema"`
+	SearchGroups    []SearchGroup `xml:"searchGroup,omitempty"`
+
+	MOSHeader `xml:"-"`
+}
+
+// GetMessageType returns the type of the message
+func (m MOSReqObjList) GetMessageType() string {
+	return "mosReqObjList"
+}
+
+// SearchGroup is a set of search fields that must all match
+type SearchGroup struct {
+	Fields []SearchField `xml:"searchField"`
+}
+
+// SearchField is a single search clause, e.g. XPath="/objType[.='VIDEO']"
+type SearchField struct {
+	XPath       string `xml:"XPath,attr"`
+	SortByOrder string `xml:"sortByOrder,attr,omitempty"`
+	SortType    string `xml:"sortType,attr,omitempty"`
+}
+
+// MOSObjList returns one page of catalog search results
+// Format: <mosObjList username=""><queryID/><listReturnStart/><listReturnEnd/><listReturnTotal/>[<listReturnStatus/>][<list/>]</mosObjList>
+type MOSObjList struct {
+	XMLName          xml.Name `xml:"mosObjList"`
+	RequestID        string   `xml:"requestID,attr,omitempty"`
+	Timestamp        string   `xml:"timestamp,attr,omitempty"`
+	Source           string   `xml:"source,attr,omitempty"`
+	Username         string   `xml:"username,attr,omitempty"`
+	QueryID          string   `xml:"queryID"`
+	ListReturnStart  int      `xml:"listReturnStart"`
+	ListReturnEnd    int      `xml:"listReturnEnd"`
+	ListReturnTotal  int      `xml:"listReturnTotal"`
+	ListReturnStatus string   `xml:"listReturnStatus,omitempty"`
+	List             *ObjList `xml:"list,omitempty"`
+
+	MOSHeader `xml:"-"`
+}
+
+// GetMessageType returns the type of the message
+func (m MOSObjList) GetMessageType() string {
+	return "mosObjList"
+}
+
+// ObjList holds the objects of a mosObjList
+type ObjList struct {
+	Objects []MOSObj `xml:"mosObj"`
+}
@@ -18,213 +18,154 @@ var (
 // MessageParser parses XML messages into their corresponding types
 type MessageParser struct {
 	buffer []byte
+
+	// Framing result for the current buffer: the first complete message, or
+	// the buffer length last found to hold no complete message
+	framed  *frame
+	scanned int
+	scan    frameScan
+
+	// Schema messages are validated against
+	schema *Schema
 }
 
 // NewMessageParser creates a new message parser
 func NewMessageParser() *MessageParser {
 	return &MessageParser{
-		buffer: make([]byte, 0, 4096),
+		buffer:  make([]byte, 0, 4096),
+		scanned: -1,
+		scan:    frameScan{start: -1},
 	}
 }
 
 // AppendData adds data to the parser's buffer
 func (p *MessageParser) AppendData(data []byte) {
 	p.buffer = append(p.buffer, data...)
+	if p.framed == nil {
+		p.scanned = -1
+	}
+}
+
+// SetSchema makes the parser validate every message against the schema. A
+// message breaking it is still parsed, and returned together with a
+// *ValidationError so the caller can decide whether to reject it.
+func (p *MessageParser) SetSchema(schema *Schema) {
+	p.schema = schema
 }
 
 // Clear clears the parser's buffer
 func (p *MessageParser) Clear() {
 	p.buffer = p.buffer[:0]
+	p.resetFrame()
 }
 
-// HasCompleteMessage checks if the buffer contains a complete XML message
+// HasCompleteMessage checks if the buffer contains a complete XML message,
+// or a malformed one that Parse will report and skip
 func (p *MessageParser) HasCompleteMessage() bool {
-	// Check if we have an opening and closing tag
-	if len(p.buffer) < 2 {
-		return false
-	}
-
-	// Find the opening tag
-	start := bytes.IndexByte(p.buffer, '<')
-	if start == -1 {
-		return false
-	}
-
-	// Extract the tag name
-	nameEnd := bytes.IndexAny(p.buffer[start:], " \t\n\r/>")
-	if nameEnd == -1 {
-		return false
-	}
-
-	if start+nameEnd >= len(p.buffer) {
-		return false
-	}
-
-	tagName := string(p.buffer[start+1 : start+nameEnd])
-
-	// Check for self-closing tag like <heartbeat/>
-	selfClosingEnd := bytes.Index(p.buffer[start:], []byte("/>"))
-	if selfClosingEnd != -1 {
-		return true
-	}
-
-	// Look for closing tag
-	closingTag := fmt.Sprintf("</%s>", tagName)
-	if bytes.Contains(p.buffer, []byte(closingTag)) {
-		return true
-	}
-
-	return false
+	_, err := p.nextFrame()
+	return !errors.Is(err, ErrIncompleteXML)
 }
 
 // Parse attempts to parse the buffer into a MOS message
 func (p *MessageParser) Parse() (MOSMessage, []byte, error) {
-	if !p.HasCompleteMessage() {
-		return nil, p.buffer, ErrIncompleteXML
+	found, err := p.nextFrame()
+	if errors.Is(err, ErrIncompleteXML) {
+		return nil, p.buffer, err
 	}
-
-	// Detect the message type based on the root element
-	messageType, err := p.detectMessageType()
 	if err != nil {
+		// Skip the malformed message, keeping whatever follows it
+		p.buffer = p.buffer[resyncOffset(p.buffer, found.start):]
+		p.resetFrame()
 		return nil, p.buffer, err
 	}
 
-	var message MOSMessage
-
-	// Parse based on message type
-	switch messageType {
-	case "heartbeat":
-		var heartbeat Heartbeat
-		remaining, err := p.parseMessage(&heartbeat)
-		if err != nil {
-			return nil, p.buffer, err
-		}
-		message = heartbeat
-		p.buffer = remaining
-
-	case "roReq":
-		var roReq ReqRunningOrderList
-		remaining, err := p.parseMessage(&roReq)
-		if err != nil {
-			return nil, p.buffer, err
-		}
-		message = roReq
-		p.buffer = remaining
-
-	case "roReqAll":
-		var roReqAll ReqRunningOrder
-		remaining, err := p.parseMessage(&roReqAll)
-		if err != nil {
-			return nil, p.buffer, err
-		}
-		message = roReqAll
-		p.buffer = remaining
-
-	case "roList":
-		var roList RunningOrderList
-		remaining, err := p.parseMessage(&roList)
-		if err != nil {
-			return nil, p.buffer, err
-		}
-		message = roList
-		p.buffer = remaining
-
-	case "roCreate":
-		var roCreate RunningOrderInfo
-		remaining, err := p.parseMessage(&roCreate)
-		if err != nil {
-			return nil, p.buffer, err
-		}
-		message = roCreate
-		p.buffer = remaining
-
-	case "mosAck":
-		var mosAck MOSAck
-		remaining, err := p.parseMessage(&mosAck)
-		if err != nil {
-			return nil, p.buffer, err
-		}
-		message = mosAck
-		p.buffer = remaining
-
-	case "ncsReqStoryAction":
-		var ncsReqStoryAction NCSReqStoryAction
-		remaining, err := p.parseMessage(&ncsReqStoryAction)
-		if err != nil {
-			return nil, p.buffer, err
-		}
-		message = ncsReqStoryAction
-		p.buffer = remaining
+	// Detect the message type based on the root element inside the envelope
+	messageType, err := p.detectMessageType()
+	if err != nil {
+		return nil, p.discardMessage(), err
+	}
 
-	default:
-		return nil, p.buffer, fmt.Errorf("%w: %s", ErrUnknownMessage, messageType)
+	decode, ok := decoderFor(messageType)
+	if !ok {
+		return nil, p.discardMessage(), fmt.Errorf("%w: %s", ErrUnknownMessage, messageType)
 	}
 
-	return message, p.buffer, nil
+	message, err := p.parseMessage(messageType, decode)
+	return message, p.buffer, err
 }
 
-// detectMessageType determines the type of message in the buffer
+// detectMessageType determines the type of message in the buffer.
+// For enveloped messages this is the first element after the header fields.
 func (p *MessageParser) detectMessageType() (string, error) {
-	start := bytes.IndexByte(p.buffer, '<')
-	if start == -1 {
-		return "", ErrInvalidXML
+	found, err := p.nextFrame()
+	if err != nil {
+		return "", err
 	}
 
-	nameEnd := bytes.IndexAny(p.buffer[start:], " \t\n\r/>")
-	if nameEnd == -1 {
-		return "", ErrInvalidXML
+	_, payload, err := unwrapEnvelope(p.buffer[found.start:found.end])
+	if err != nil {
+		return "", err
 	}
 
-	if start+nameEnd >= len(p.buffer) {
-		return "", ErrInvalidXML
+	return rootName(payload)
+}
+
+// discardMessage drops the first complete message from the buffer and returns the remaining data
+func (p *MessageParser) discardMessage() []byte {
+	found, err := p.nextFrame()
+	if err != nil {
+		return p.buffer
 	}
 
-	tagName := string(p.buffer[start+1 : start+nameEnd])
-	return tagName, nil
+	p.buffer = p.buffer[found.end:]
+	p.resetFrame()
+	return p.buffer
 }
 
-// parseMessage parses the buffer into the given message type and returns the remaining data
-func (p *MessageParser) parseMessage(message interface{}) ([]byte, error) {
-	// Find the complete message
-	messageType, err := p.detectMessageType()
+// parseMessage decodes the first message in the buffer and drops it from
+// the buffer. The envelope header is copied onto the message. A message
+// breaking the schema is returned together with a *ValidationError.
+func (p *MessageParser) parseMessage(messageType string, decode MessageDecoder) (MOSMessage, error) {
+	// Find the bounds of the message
+	found, err := p.nextFrame()
 	if err != nil {
-		return p.buffer, err
+		return nil, err
 	}
 
-	// Find the end of the message
-	var messageEnd int
-
-	// Check for self-closing tag
-	selfClosingEnd := bytes.Index(p.buffer, []byte("/>"))
-	if selfClosingEnd != -1 && bytes.IndexAny(p.buffer[:selfClosingEnd], "<") == bytes.IndexByte(p.buffer, '<') {
-		// This is a self-closing tag
-		messageEnd = selfClosingEnd + 2
-	} else {
-		// Look for closing tag
-		closingTag := fmt.Sprintf("</%s>", messageType)
-		closingTagIndex := bytes.Index(p.buffer, []byte(closingTag))
-		if closingTagIndex == -1 {
-			return p.buffer, ErrIncompleteXML
-		}
-		messageEnd = closingTagIndex + len(closingTag)
-	}
+	data := p.buffer[found.start:found.end]
+	p.buffer = p.buffer[found.end:]
+	p.resetFrame()
 
-	// Parse the message
-	if messageEnd > len(p.buffer) {
-		return p.buffer, ErrIncompleteXML
+	// Unwrap the <mos> envelope
+	header, payload, err := unwrapEnvelope(data)
+	if err != nil {
+		return nil, err
 	}
 
-	err = xml.Unmarshal(p.buffer[:messageEnd], message)
+	message, err := decode(payload)
 	if err != nil {
-		return p.buffer, fmt.Errorf("failed to unmarshal XML: %w", err)
+		return nil, err
 	}
+	message = WithHeader(message, header)
 
-	// Return the remaining data
-	if messageEnd >= len(p.buffer) {
-		return []byte{}, nil
+	if p.schema != nil {
+		if violations := p.schema.Validate(payload); len(violations) > 0 {
+			return message, &ValidationError{
+				MessageType: messageType,
+				Header:      header,
+				Violations:  violations,
+			}
+		}
 	}
 
-	return p.buffer[messageEnd:], nil
+	return message, nil
+}
+
+// newDecoder creates an XML decoder for data already converted to UTF-8
+func newDecoder(data []byte) *xml.Decoder {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = charsetReader
+	return decoder
 }
 
 // ParseMessage parses a complete XML string into a MOS message
@@ -0,0 +1,107 @@
+package xml
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// MessageDecoder decodes the payload of a message, the root element inside
+// the <mos> envelope. The parser copies the envelope header onto the result.
+type MessageDecoder func(payload []byte) (MOSMessage, error)
+
+// Registered decoders by message type
+var (
+	decoders   = make(map[string]MessageDecoder)
+	decodersMu sync.RWMutex
+)
+
+func init() {
+	// Profile 0 - Basic Communication
+	RegisterMessage("heartbeat", Heartbeat{})
+	RegisterMessage("reqMachInfo", ReqMachInfo{})
+	RegisterMessage("listMachInfo", ListMachInfo{})
+
+	// Profile 1 - Basic Object Based Workflow
+	RegisterMessage("mosAck", MOSAck{})
+	RegisterMessage("mosObj", MOSObj{})
+	RegisterMessage("mosReqObj", MOSReqObj{})
+	RegisterMessage("mosReqAll", MOSReqAll{})
+	RegisterMessage("mosListAll", MOSListAll{})
+
+	// Profile 2 - Basic Running Order Workflow
+	RegisterMessage("roCreate", RunningOrderInfo{})
+	RegisterMessage("roReplace", ROReplace{})
+	RegisterMessage("roMetadataReplace", ROMetadataReplace{})
+	RegisterMessage("roDelete", RODelete{})
+	RegisterMessage("roReq", ReqRunningOrder{})
+	RegisterMessage("roList", ROList{})
+	RegisterMessage("roAck", ROAck{})
+	RegisterMessage("roStoryInsert", ROStoryInsert{})
+	RegisterMessage("roStoryAppend", ROStoryAppend{})
+	RegisterMessage("roStoryReplace", ROStoryReplace{})
+	RegisterMessage("roStoryMove", ROStoryMove{})
+	RegisterMessage("roStorySwap", ROStorySwap{})
+	RegisterMessage("roStoryDelete", ROStoryDelete{})
+	RegisterMessage("roStoryMoveMultiple", ROStoryMoveMultiple{})
+	RegisterMessage("roItemInsert", ROItemInsert{})
+	RegisterMessage("roItemReplace", ROItemReplace{})
+	RegisterMessage("roItemMoveMultiple", ROItemMoveMultiple{})
+	RegisterMessage("roItemDelete", ROItemDelete{})
+	RegisterMessage("roElementAction", ROElementAction{})
+	RegisterMessage("roReadyToAir", ROReadyToAir{})
+	RegisterMessage("roElementStat", ROElementStat{})
+	RegisterMessage("roStoryStat", ROStoryStat{})
+	RegisterMessage("roItemStat", ROItemStat{})
+
+	// Profile 3 - Advanced Object Based Workflow
+	RegisterMessage("mosObjCreate", MOSObjCreate{})
+	RegisterMessage("mosReqObjAction", MOSReqObjAction{})
+	RegisterMessage("mosItemReplace", MOSItemReplace{})
+	RegisterMessage("mosReqSearchableSchema", MOSReqSearchableSchema{})
+	RegisterMessage("mosReqObjList", MOSReqObjList{})
+
+	// Profile 4 - Advanced RO/Content List Workflow
+	RegisterMessage("roReqAll", ReqRunningOrderList{})
+	RegisterMessage("roListAll", RunningOrderList{})
+	RegisterMessage("roStorySend", ROStorySend{})
+
+	// Profile 5 - Item Control
+	RegisterMessage("roCtrl", ROCtrl{})
+	RegisterMessage("roItemCue", ROItemCue{})
+
+	// Profile 8 - ActiveX plug-ins
+	RegisterMessage("ncsReqStoryAction", NCSReqStoryAction{})
+}
+
+// RegisterDecoder registers the decoder of the messages with the given root
+// element, replacing any previous one. Vendor-specific messages are added
+// this way.
+func RegisterDecoder(messageType string, decode MessageDecoder) {
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+	decoders[messageType] = decode
+}
+
+// RegisterMessage registers a message type decoded with encoding/xml into a
+// value of the prototype's type. The type must embed MOSHeader to receive
+// the envelope header.
+func RegisterMessage(messageType string, prototype MOSMessage) {
+	messageValue := reflect.TypeOf(prototype)
+
+	RegisterDecoder(messageType, func(payload []byte) (MOSMessage, error) {
+		value := reflect.New(messageValue)
+		if err := newDecoder(payload).Decode(value.Interface()); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal XML: %w", err)
+		}
+		return value.Elem().Interface().(MOSMessage), nil
+	})
+}
+
+// decoderFor returns the decoder registered for a message type
+func decoderFor(messageType string) (MessageDecoder, bool) {
+	decodersMu.RLock()
+	defer decodersMu.RUnlock()
+	decode, ok := decoders[messageType]
+	return decode, ok
+}
@@ -0,0 +1,533 @@
+package xml
+
+import (
+	"encoding/xml"
+)
+
+// RODelete represents a request to delete a running order
+// Format: <roDelete><roID/></roDelete>
+type RODelete struct {
+	XMLName   xml.Name `xml:"roDelete"`
+	RequestID string   `xml:"requestID,attr,omitempty"`
+	Timestamp string   `xml:"timestamp,attr,omitempty"`
+	Source    string   `xml:"source,attr,omitempty"`
+	ROID      string   `xml:"roID"`
+
+	OnAirOverride
+	MOSHeader `xml:"-"`
+}
+
+// GetMessageType returns the type of the message
+func (r RODelete) GetMessageType() string {
+	return "roDelete"
+}
+
+// OnAirOverride marks a running order change as allowed while the running
+// order is ready to air. It is carried as an onAirOverride="true" attribute.
+type OnAirOverride struct {
+	Override bool `xml:"onAirOverride,attr,omitempty"`
+}
+
+// IsOnAirOverride reports whether the change is an on-air override
+func (o OnAirOverride) IsOnAirOverride() bool {
+	return o.Override
+}
+
+// ROReadyToAir represents an NCS flagging a running order as ready, or not
+// ready, to air
+// Format: <roReadyToAir><roID/><roAir/></roReadyToAir>
+type ROReadyToAir struct {
+	XMLName   xml.Name `xml:"roReadyToAir"`
+	RequestID string   `xml:"requestID,attr,omitempty"`
+	Timestamp string   `xml:"timestamp,attr,omitempty"`
+	Source    string   `xml:"source,attr,omitempty"`
+	ROID      string   `xml:"roID"`
+	ROAir     string   `xml:"roAir"`
+
+	MOSHeader `xml:"-"`
+}
+
+// GetMessageType returns the type of the message
+func (r ROReadyToAir) GetMessageType() string {
+	return "roReadyToAir"
+}
+
+// ROAck represents an acknowledgment of a running order message
+// Format: <roAck><roID/><roStatus/>[<storyID/><itemID/><objID/><itemChannel/><status/>]*</roAck>
+type ROAck struct {
+	XMLName   xml.Name       `xml:"roAck"`
+	RequestID string         `xml:"requestID,attr,omitempty"`
+	Timestamp string         `xml:"timestamp,attr,omitempty"`
+	Source    string         `xml:"source,attr,omitempty"`
+	ROID      string         `xml:"roID"`
+	ROStatus  string         `xml:"roStatus"`
+	Elements  []ROAckElement `xml:"element,omitempty"`
+
+	MOSHeader `xml:"-"`
+}
+
+// GetMessageType returns the type of the message
+func (r ROAck) GetMessageType() string {
+	return "roAck"
+}
+
+// UnmarshalXML decodes an roAck, grouping the flat storyID/itemID/objID/
+// itemChannel/status sequence into elements. Each storyID starts a new element.
+func (r *ROAck) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	r.XMLName = start.Name
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "requestID":
+			r.RequestID = attr.Value
+		case "timestamp":
+			r.Timestamp = attr.Value
+		case "source":
+			r.Source = attr.Value
+		}
+	}
+
+	current := func() *ROAckElement {
+		if len(r.Elements) == 0 {
+			r.Elements = append(r.Elements, ROAckElement{})
+		}
+		return &r.Elements[len(r.Elements)-1]
+	}
+
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			var value string
+			if err := d.DecodeElement(&value, &t); err != nil {
+				return err
+			}
+
+			switch t.Name.Local {
+			case "roID":
+				r.ROID = value
+			case "roStatus":
+				r.ROStatus = value
+			case "storyID":
+				r.Elements = append(r.Elements, ROAckElement{StoryID: value})
+			case "itemID":
+				current().ItemID = value
+			case "objID":
+				current().ObjID = value
+			case "itemChannel":
+				current().ItemChannel = value
+			case "status":
+				current().Status = value
+			}
+
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// ROAckElement reports the status of a single story, item or object in an roAck
+type ROAckElement struct {
+	StoryID     string `xml:"storyID"`
+	ItemID      string `xml:"itemID"`
+	ObjID       string `xml:"objID"`
+	ItemChannel string `xml:"itemChannel,omitempty"`
+	Status      string `xml:"status"`
+}
+
+// MarshalXML writes the element fields as flat siblings inside the roAck
+// without a wrapping element, as required by the MOS specification. Story
+// results carry empty itemID and objID elements, which the schema requires
+// in every tuple.
+func (e ROAckElement) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	fields := []struct {
+		name     string
+		value    string
+		optional bool
+	}{
+		{"storyID", e.StoryID, false},
+		{"itemID", e.ItemID, false},
+		{"objID", e.ObjID, false},
+		{"itemChannel", e.ItemChannel, true},
+		{"status", e.Status, false},
+	}
+
+	for _, field := range fields {
+		if field.optional && field.value == "" {
+			continue
+		}
+		if err := enc.EncodeElement(field.value, xml.StartElement{Name: xml.Name{Local: field.name}}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ROMetadataReplace represents a replacement of running order header fields
+// without touching its stories
+type ROMetadataReplace struct {
+	XMLName      xml.Name              `xml:"roMetadataReplace"`
+	RequestID    string                `xml:"requestID,attr,omitempty"`
+	Timestamp    string                `xml:"timestamp,attr,omitempty"`
+	Source       string                `xml:"source,attr,omitempty"`
+	ROID         string                `xml:"roID"`
+	Slug         string                `xml:"roSlug"`
+	Channel      string                `xml:"roChannel,omitempty"`
+	EdStart      string                `xml:"roEdStart,omitempty"`
+	EdDur        string                `xml:"roEdDur,omitempty"`
+	Trigger      string                `xml:"roTrigger,omitempty"`
+	MacroIn      string                `xml:"macroIn,omitempty"`
+	MacroOut     string                `xml:"macroOut,omitempty"`
+	ExternalMeta []MosExternalMetadata `xml:"mosExternalMetadata,omitempty"`
+
+	MOSHeader `xml:"-"`
+}
+
+// GetMessageType returns the type of the message
+func (r ROMetadataReplace) GetMessageType() string {
+	return "roMetadataReplace"
+}
+
+// ROStoryInsert represents insertion of stories before a target story
+// Format: <roStoryInsert><roID/><storyID/><story/>+</roStoryInsert>
+type ROStoryInsert struct {
+	XMLName   xml.Name    `xml:"roStoryInsert"`
+	RequestID string      `xml:"requestID,attr,omitempty"`
+	Timestamp string      `xml:"timestamp,attr,omitempty"`
+	Source    string      `xml:"source,attr,omitempty"`
+	ROID      string      `xml:"roID"`
+	StoryID   string      `xml:"storyID"`
+	Stories   []StoryInfo `xml:"story"`
+
+	OnAirOverride
+	MOSHeader `xml:"-"`
+}
+
+// GetMessageType returns the type of the message
+func (r ROStoryInsert) GetMessageType() string {
+	return "roStoryInsert"
+}
+
+// ROStoryAppend represents stories appended to the end of a running order
+// Format: <roStoryAppend><roID/><story/>+</roStoryAppend>
+type ROStoryAppend struct {
+	XMLName   xml.Name    `xml:"roStoryAppend"`
+	RequestID string      `xml:"requestID,attr,omitempty"`
+	Timestamp string      `xml:"timestamp,attr,omitempty"`
+	Source    string      `xml:"source,attr,omitempty"`
+	ROID      string      `xml:"roID"`
+	Stories   []StoryInfo `xml:"story"`
+
+	OnAirOverride
+	MOSHeader `xml:"-"`
+}
+
+// GetMessageType returns the type of the message
+func (r ROStoryAppend) GetMessageType() string {
+	return "roStoryAppend"
+}
+
+// ROStoryReplace represents the replacement of a story with one or more stories
+// Format: <roStoryReplace><roID/><storyID/><story/>+</roStoryReplace>
+type ROStoryReplace struct {
+	XMLName   xml.Name    `xml:"roStoryReplace"`
+	RequestID string      `xml:"requestID,attr,omitempty"`
+	Timestamp string      `xml:"timestamp,attr,omitempty"`
+	Source    string      `xml:"source,attr,omitempty"`
+	ROID      string      `xml:"roID"`
+	StoryID   string      `xml:"storyID"`
+	Stories   []StoryInfo `xml:"story"`
+
+	OnAirOverride
+	MOSHeader `xml:"-"`
+}
+
+// GetMessageType returns the type of the message
+func (r ROStoryReplace) GetMessageType() string {
+	return "roStoryReplace"
+}
+
+// ROStoryMove represents moving a story before another story.
+// The first storyID is moved before the second; an empty second storyID moves it to the end.
+// Format: <roStoryMove><roID/><storyID/><storyID/></roStoryMove>
+type ROStoryMove struct {
+	XMLName   xml.Name `xml:"roStoryMove"`
+	RequestID string   `xml:"requestID,attr,omitempty"`
+	Timestamp string   `xml:"timestamp,attr,omitempty"`
+	Source    string   `xml:"source,attr,omitempty"`
+	ROID      string   `xml:"roID"`
+	StoryIDs  []string `xml:"storyID"`
+
+	OnAirOverride
+	MOSHeader `xml:"-"`
+}
+
+// GetMessageType returns the type of the message
+func (r ROStoryMove) GetMessageType() string {
+	return "roStoryMove"
+}
+
+// ROStorySwap represents swapping the positions of two stories
+// Format: <roStorySwap><roID/><storyID/><storyID/></roStorySwap>
+type ROStorySwap struct {
+	XMLName   xml.Name `xml:"roStorySwap"`
+	RequestID string   `xml:"requestID,attr,omitempty"`
+	Timestamp string   `xml:"timestamp,attr,omitempty"`
+	Source    string   `xml:"source,attr,omitempty"`
+	ROID      string   `xml:"roID"`
+	StoryIDs  []string `xml:"storyID"`
+
+	OnAirOverride
+	MOSHeader `xml:"-"`
+}
+
+// GetMessageType returns the type of the message
+func (r ROStorySwap) GetMessageType() string {
+	return "roStorySwap"
+}
+
+// ROStoryDelete represents the deletion of one or more stories
+// Format: <roStoryDelete><roID/><storyID/>+</roStoryDelete>
+type ROStoryDelete struct {
+	XMLName   xml.Name `xml:"roStoryDelete"`
+	RequestID string   `xml:"requestID,attr,omitempty"`
+	Timestamp string   `xml:"timestamp,attr,omitempty"`
+	Source    string   `xml:"source,attr,omitempty"`
+	ROID      string   `xml:"roID"`
+	StoryIDs  []string `xml:"storyID"`
+
+	OnAirOverride
+	MOSHeader `xml:"-"`
+}
+
+// GetMessageType returns the type of the message
+func (r ROStoryDelete) GetMessageType() string {
+	return "roStoryDelete"
+}
+
+// ROStoryMoveMultiple represents moving several stories before a target story.
+// The last storyID is the target; an empty target moves the stories to the end.
+// Format: <roStoryMoveMultiple><roID/><storyID/>+</roStoryMoveMultiple>
+type ROStoryMoveMultiple struct {
+	XMLName   xml.Name `xml:"roStoryMoveMultiple"`
+	RequestID string   `xml:"requestID,attr,omitempty"`
+	Timestamp string   `xml:"timestamp,attr,omitempty"`
+	Source    string   `xml:"source,attr,omitempty"`
+	ROID      string   `xml:"roID"`
+	StoryIDs  []string `xml:"storyID"`
+
+	OnAirOverride
+	MOSHeader `xml:"-"`
+}
+
+// GetMessageType returns the type of the message
+func (r ROStoryMoveMultiple) GetMessageType() string {
+	return "roStoryMoveMultiple"
+}
+
+// ROElementAction represents a MOS 2.8.2+ running order change applied to
+// stories, or to the items of a story when the source carries items.
+// Operation is one of INSERT, REPLACE, MOVE, DELETE or SWAP.
+// Format: <roElementAction operation=""><roID/>[<element_target/>]<element_source/></roElementAction>
+type ROElementAction struct {
+	XMLName   xml.Name      `xml:"roElementAction"`
+	RequestID string        `xml:"requestID,attr,omitempty"`
+	Timestamp string        `xml:"timestamp,attr,omitempty"`
+	Source    string        `xml:"source,attr,omitempty"`
+	Operation string        `xml:"operation,attr"`
+	ROID      string        `xml:"roID"`
+	Target    ElementTarget `xml:"element_target"`
+	Elements  ElementSource `xml:"element_source"`
+
+	OnAirOverride
+	MOSHeader `xml:"-"`
+}
+
+// GetMessageType returns the type of the message
+func (r ROElementAction) GetMessageType() string {
+	return "roElementAction"
+}
+
+// ElementTarget identifies the story, or the item within a story, that an
+// roElementAction is applied relative to
+type ElementTarget struct {
+	StoryID string `xml:"storyID,omitempty"`
+	ItemID  string `xml:"itemID,omitempty"`
+}
+
+// ElementSource carries the stories or items of an roElementAction, either
+// in full for INSERT and REPLACE or by ID for MOVE, DELETE and SWAP
+type ElementSource struct {
+	Stories  []StoryInfo `xml:"story,omitempty"`
+	Items    []ItemInfo  `xml:"item,omitempty"`
+	StoryIDs []string    `xml:"storyID,omitempty"`
+	ItemIDs  []string    `xml:"itemID,omitempty"`
+}
+
+// TargetsItems reports whether the source refers to items rather than stories
+func (e ElementSource) TargetsItems() bool {
+	return len(e.Items) > 0 || len(e.ItemIDs) > 0
+}
+
+// ROItemInsert represents insertion of items into a story before a target item.
+// An empty target itemID appends the items to the end of the story.
+// Format: <roItemInsert><roID/><storyID/><itemID/><item/>+</roItemInsert>
+type ROItemInsert struct {
+	XMLName   xml.Name   `xml:"roItemInsert"`
+	RequestID string     `xml:"requestID,attr,omitempty"`
+	Timestamp string     `xml:"timestamp,attr,omitempty"`
+	Source    string     `xml:"source,attr,omitempty"`
+	ROID      string     `xml:"roID"`
+	StoryID   string     `xml:"storyID"`
+	ItemID    string     `xml:"itemID"`
+	Items     []ItemInfo `xml:"item"`
+
+	OnAirOverride
+	MOSHeader `xml:"-"`
+}
+
+// GetMessageType returns the type of the message
+func (r ROItemInsert) GetMessageType() string {
+	return "roItemInsert"
+}
+
+// ROItemReplace represents the replacement of an item with one or more items
+// Format: <roItemReplace><roID/><storyID/><itemID/><item/>+</roItemReplace>
+type ROItemReplace struct {
+	XMLName   xml.Name   `xml:"roItemReplace"`
+	RequestID string     `xml:"requestID,attr,omitempty"`
+	Timestamp string     `xml:"timestamp,attr,omitempty"`
+	Source    string     `xml:"source,attr,omitempty"`
+	ROID      string     `xml:"roID"`
+	StoryID   string     `xml:"storyID"`
+	ItemID    string     `xml:"itemID"`
+	Items     []ItemInfo `xml:"item"`
+
+	OnAirOverride
+	MOSHeader `xml:"-"`
+}
+
+// GetMessageType returns the type of the message
+func (r ROItemReplace) GetMessageType() string {
+	return "roItemReplace"
+}
+
+// ROItemMoveMultiple represents moving several items of a story before a target item.
+// The last itemID is the target; an empty target moves the items to the end.
+// Format: <roItemMoveMultiple><roID/><storyID/><itemID/>+</roItemMoveMultiple>
+type ROItemMoveMultiple struct {
+	XMLName   xml.Name `xml:"roItemMoveMultiple"`
+	RequestID string   `xml:"requestID,attr,omitempty"`
+	Timestamp string   `xml:"timestamp,attr,omitempty"`
+	Source    string   `xml:"source,attr,omitempty"`
+	ROID      string   `xml:"roID"`
+	StoryID   string   `xml:"storyID"`
+	ItemIDs   []string `xml:"itemID"`
+
+	OnAirOverride
+	MOSHeader `xml:"-"`
+}
+
+// GetMessageType returns the type of the message
+func (r ROItemMoveMultiple) GetMessageType() string {
+	return "roItemMoveMultiple"
+}
+
+// ROItemDelete represents the deletion of one or more items from a story
+// Format: <roItemDelete><roID/><storyID/><itemID/>+</roItemDelete>
+type ROItemDelete struct {
+	XMLName   xml.Name `xml:"roItemDelete"`
+	RequestID string   `xml:"requestID,attr,omitempty"`
+	Timestamp string   `xml:"timestamp,attr,omitempty"`
+	Source    string   `xml:"source,attr,omitempty"`
+	ROID      string   `xml:"roID"`
+	StoryID   string   `xml:"storyID"`
+	ItemIDs   []string `xml:"itemID"`
+
+	OnAirOverride
+	MOSHeader `xml:"-"`
+}
+
+// GetMessageType returns the type of the message
+func (r ROItemDelete) GetMessageType() string {
+	return "roItemDelete"
+}
+
+// Elements reported by roElementStat
+const (
+	ElementRunningOrder = "RO"
+	ElementStory        = "STORY"
+	ElementItem         = "ITEM"
+)
+
+// ROElementStat reports the status of a running order, story or item to the
+// NCS (MOS 3 and later)
+// Format: <roElementStat element=""><roID/>[<storyID/>[<itemID/><objID/><itemChannel/>]]<status/><time/></roElementStat>
+type ROElementStat struct {
+	XMLName     xml.Name `xml:"roElementStat"`
+	Element     string   `xml:"element,attr"`
+	RequestID   string   `xml:"requestID,attr,omitempty"`
+	Timestamp   string   `xml:"timestamp,attr,omitempty"`
+	Source      string   `xml:"source,attr,omitempty"`
+	ROID        string   `xml:"roID"`
+	StoryID     string   `xml:"storyID,omitempty"`
+	ItemID      string   `xml:"itemID,omitempty"`
+	ObjID       string   `xml:"objID,omitempty"`
+	ItemChannel string   `xml:"itemChannel,omitempty"`
+	Status      string   `xml:"status"`
+	Time        string   `xml:"time"`
+
+	MOSHeader `xml:"-"`
+}
+
+// GetMessageType returns the type of the message
+func (r ROElementStat) GetMessageType() string {
+	return "roElementStat"
+}
+
+// ROStoryStat reports the status of a story to the NCS (MOS 2.8)
+// Format: <roStoryStat><roID/><storyID/><status/><time/></roStoryStat>
+type ROStoryStat struct {
+	XMLName   xml.Name `xml:"roStoryStat"`
+	RequestID string   `xml:"requestID,attr,omitempty"`
+	Timestamp string   `xml:"timestamp,attr,omitempty"`
+	Source    string   `xml:"source,attr,omitempty"`
+	ROID      string   `xml:"roID"`
+	StoryID   string   `xml:"storyID"`
+	Status    string   `xml:"status"`
+	Time      string   `xml:"time"`
+
+	MOSHeader `xml:"-"`
+}
+
+// GetMessageType returns the type of the message
+func (r ROStoryStat) GetMessageType() string {
+	return "roStoryStat"
+}
+
+// ROItemStat reports the status of an item to the NCS (MOS 2.8)
+// Format: <roItemStat><roID/><storyID/><itemID/><objID/><itemChannel/><status/><time/></roItemStat>
+type ROItemStat struct {
+	XMLName     xml.Name `xml:"roItemStat"`
+	RequestID   string   `xml:"requestID,attr,omitempty"`
+	Timestamp   string   `xml:"timestamp,attr,omitempty"`
+	Source      string   `xml:"source,attr,omitempty"`
+	ROID        string   `xml:"roID"`
+	StoryID     string   `xml:"storyID"`
+	ItemID      string   `xml:"itemID"`
+	ObjID       string   `xml:"objID"`
+	ItemChannel string   `xml:"itemChannel,omitempty"`
+	Status      string   `xml:"status"`
+	Time        string   `xml:"time"`
+
+	MOSHeader `xml:"-"`
+}
+
+// GetMessageType returns the type of the message
+func (r ROItemStat) GetMessageType() string {
+	return "roItemStat"
+}
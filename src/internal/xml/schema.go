@@ -0,0 +1,612 @@
+package xml
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrSchemaViolation is wrapped by errors for messages that break the MOS schema
+var ErrSchemaViolation = errors.New("schema violation")
+
+// Schema holds the rules of the MOS XSD used to validate messages: the
+// content model of every element, with the order and occurrences of its
+// children, its attributes and the enumerations and length limits on their
+// values. Children the content model does not mention are accepted as they
+// are, so MOS 4 additions and vendor extensions pass.
+type Schema struct {
+	elements map[string]*schemaElement
+}
+
+// schemaElement is the compiled definition of a top-level XSD element
+type schemaElement struct {
+	name       string
+	content    *particle // Nil for elements with simple content
+	opaque     bool      // Content is xsd:any and is not validated
+	attributes []schemaAttribute
+	facets     facets
+}
+
+// schemaAttribute is an attribute declared on an element
+type schemaAttribute struct {
+	name     string
+	required bool
+	facets   facets
+}
+
+// particle is a node of an element's content model
+type particle struct {
+	kind      string // element, sequence, choice or any
+	name      string // Referenced element name for kind element
+	minOccurs int
+	maxOccurs int // -1 when unbounded
+	children  []*particle
+}
+
+// facets restricts the value of an element or attribute
+type facets struct {
+	enumeration []string
+	minLength   int // -1 when unrestricted
+	maxLength   int // -1 when unrestricted
+}
+
+// Violation is a schema rule broken by a message
+type Violation struct {
+	Path    string // Element path from the message root, e.g. roCreate/story[2]/storyID
+	Message string
+}
+
+// String formats the violation for logs and NACK descriptions
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s", v.Path, v.Message)
+}
+
+// ValidationError reports the schema violations found in a parsed message
+type ValidationError struct {
+	MessageType string
+	Header      MOSHeader
+	Violations  []Violation
+}
+
+// Error lists the violations of the message
+func (e *ValidationError) Error() string {
+	descriptions := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		descriptions[i] = violation.String()
+	}
+	return fmt.Sprintf("%s violates the MOS schema: %s", e.MessageType, strings.Join(descriptions, "; "))
+}
+
+// Unwrap makes the error match ErrSchemaViolation
+func (e *ValidationError) Unwrap() error {
+	return ErrSchemaViolation
+}
+
+// xmlNode is a generic XML element, used both to read the XSD and to walk
+// the messages being validated
+type xmlNode struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	Text     string     `xml:",chardata"`
+	Children []xmlNode  `xml:",any"`
+}
+
+// attr returns the value of the named attribute
+func (n xmlNode) attr(name string) (string, bool) {
+	for _, attr := range n.Attrs {
+		if attr.Name.Local == name {
+			return attr.Value, true
+		}
+	}
+	return "", false
+}
+
+// child returns the first child element with the given name
+func (n xmlNode) child(name string) (xmlNode, bool) {
+	for _, child := range n.Children {
+		if child.XMLName.Local == name {
+			return child, true
+		}
+	}
+	return xmlNode{}, false
+}
+
+// LoadSchema reads the MOS XSD from a file
+func LoadSchema(path string) (*Schema, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	schema, err := ParseSchema(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return schema, nil
+}
+
+// ParseSchema compiles an XSD document. Only the constructs used by the MOS
+// schema are supported: top-level elements referencing each other, sequences,
+// choices, xsd:any, attributes and string restrictions.
+func ParseSchema(reader io.Reader) (*Schema, error) {
+	var root xmlNode
+	decoder := xml.NewDecoder(reader)
+	decoder.CharsetReader = charsetReader
+	if err := decoder.Decode(&root); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidXML, err)
+	}
+	if root.XMLName.Local != "schema" {
+		return nil, fmt.Errorf("%w: root element is %s, not schema", ErrInvalidXML, root.XMLName.Local)
+	}
+
+	schema := &Schema{elements: make(map[string]*schemaElement)}
+	for _, node := range root.Children {
+		if node.XMLName.Local != "element" {
+			continue
+		}
+		element, err := compileElement(node)
+		if err != nil {
+			return nil, err
+		}
+		schema.elements[element.name] = element
+	}
+
+	if len(schema.elements) == 0 {
+		return nil, fmt.Errorf("%w: schema defines no elements", ErrInvalidXML)
+	}
+	return schema, nil
+}
+
+// compileElement compiles a top-level element definition
+func compileElement(node xmlNode) (*schemaElement, error) {
+	name, _ := node.attr("name")
+	if name == "" {
+		return nil, fmt.Errorf("%w: top-level element without a name", ErrInvalidXML)
+	}
+
+	element := &schemaElement{name: name, facets: noFacets()}
+
+	if simpleType, ok := node.child("simpleType"); ok {
+		element.facets = compileFacets(simpleType)
+	}
+
+	complexType, ok := node.child("complexType")
+	if !ok {
+		return element, nil
+	}
+
+	// Complex types without a content model, such as <reqMachInfo/>, are empty
+	element.content = &particle{kind: "sequence", minOccurs: 1, maxOccurs: 1}
+
+	for _, child := range complexType.Children {
+		switch child.XMLName.Local {
+		case "sequence", "choice":
+			content, err := compileParticle(child)
+			if err != nil {
+				return nil, fmt.Errorf("element %s: %w", name, err)
+			}
+			element.content = content
+		case "attribute":
+			element.attributes = append(element.attributes, compileAttribute(child))
+		case "simpleContent":
+			// Text with attributes, e.g. <objPath techDescription="...">
+			element.content = nil
+			for _, extension := range child.Children {
+				for _, attribute := range extension.Children {
+					if attribute.XMLName.Local == "attribute" {
+						element.attributes = append(element.attributes, compileAttribute(attribute))
+					}
+				}
+			}
+		}
+	}
+
+	element.opaque = element.content != nil && element.content.hasAny()
+	return element, nil
+}
+
+// compileParticle compiles a sequence, choice, element reference or xsd:any
+func compileParticle(node xmlNode) (*particle, error) {
+	p := &particle{kind: node.XMLName.Local, minOccurs: 1, maxOccurs: 1}
+
+	if value, ok := node.attr("minOccurs"); ok {
+		minOccurs, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid minOccurs %q", ErrInvalidXML, value)
+		}
+		p.minOccurs = minOccurs
+	}
+	if value, ok := node.attr("maxOccurs"); ok {
+		if value == "unbounded" {
+			p.maxOccurs = -1
+		} else {
+			maxOccurs, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid maxOccurs %q", ErrInvalidXML, value)
+			}
+			p.maxOccurs = maxOccurs
+		}
+	}
+
+	switch p.kind {
+	case "element":
+		p.name, _ = node.attr("ref")
+		if p.name == "" {
+			p.name, _ = node.attr("name")
+		}
+	case "sequence", "choice":
+		for _, child := range node.Children {
+			switch child.XMLName.Local {
+			case "element", "sequence", "choice", "any":
+				compiled, err := compileParticle(child)
+				if err != nil {
+					return nil, err
+				}
+				p.children = append(p.children, compiled)
+			}
+		}
+	}
+
+	return p, nil
+}
+
+// compileAttribute compiles an attribute declaration
+func compileAttribute(node xmlNode) schemaAttribute {
+	attribute := schemaAttribute{facets: noFacets()}
+	attribute.name, _ = node.attr("name")
+	use, _ := node.attr("use")
+	attribute.required = use == "required"
+	if simpleType, ok := node.child("simpleType"); ok {
+		attribute.facets = compileFacets(simpleType)
+	}
+	return attribute
+}
+
+// compileFacets reads the enumeration and length facets of a simple type
+func compileFacets(simpleType xmlNode) facets {
+	result := noFacets()
+
+	restriction, ok := simpleType.child("restriction")
+	if !ok {
+		return result
+	}
+
+	for _, facet := range restriction.Children {
+		value, _ := facet.attr("value")
+		switch facet.XMLName.Local {
+		case "enumeration":
+			result.enumeration = append(result.enumeration, value)
+		case "minLength":
+			if n, err := strconv.Atoi(value); err == nil {
+				result.minLength = n
+			}
+		case "maxLength":
+			if n, err := strconv.Atoi(value); err == nil {
+				result.maxLength = n
+			}
+		case "length":
+			if n, err := strconv.Atoi(value); err == nil {
+				result.minLength, result.maxLength = n, n
+			}
+		}
+	}
+
+	return result
+}
+
+// noFacets returns an unrestricted value
+func noFacets() facets {
+	return facets{minLength: -1, maxLength: -1}
+}
+
+// hasAny reports whether the content model accepts arbitrary elements
+func (p *particle) hasAny() bool {
+	if p.kind == "any" {
+		return true
+	}
+	for _, child := range p.children {
+		if child.hasAny() {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks a message payload, such as <roCreate>...</roCreate>, against
+// the schema and returns the violations found
+func (s *Schema) Validate(payload []byte) []Violation {
+	var root xmlNode
+	if err := newDecoder(payload).Decode(&root); err != nil {
+		return []Violation{{Message: fmt.Sprintf("invalid XML: %v", err)}}
+	}
+
+	var violations []Violation
+	s.validateNode(root, root.XMLName.Local, &violations)
+	return violations
+}
+
+// ValidateMessage checks a complete message, with or without its <mos>
+// envelope, and returns a *ValidationError when it breaks the schema
+func (s *Schema) ValidateMessage(data []byte) error {
+	header, payload, err := unwrapEnvelope(data)
+	if err != nil {
+		return err
+	}
+
+	violations := s.Validate(payload)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	messageType, _ := rootName(payload)
+	return &ValidationError{
+		MessageType: messageType,
+		Header:      header,
+		Violations:  violations,
+	}
+}
+
+// validateNode checks an element and its descendants
+func (s *Schema) validateNode(node xmlNode, path string, violations *[]Violation) {
+	element, ok := s.elements[node.XMLName.Local]
+	if !ok {
+		return
+	}
+
+	for _, attribute := range element.attributes {
+		value, present := node.attr(attribute.name)
+		if !present {
+			if attribute.required {
+				*violations = append(*violations, Violation{Path: path, Message: fmt.Sprintf("missing required attribute %s", attribute.name)})
+			}
+			continue
+		}
+		if problem := attribute.facets.check(value); problem != "" {
+			*violations = append(*violations, Violation{Path: path, Message: fmt.Sprintf("attribute %s %s", attribute.name, problem)})
+		}
+	}
+
+	if element.content == nil {
+		if problem := element.facets.check(node.Text); problem != "" {
+			*violations = append(*violations, Violation{Path: path, Message: problem})
+		}
+		return
+	}
+	if element.opaque {
+		return
+	}
+
+	// Children the content model does not mention are extensions
+	known := element.content.names(make(map[string]bool))
+	var sequence []string
+	counts := make(map[string]int)
+	for _, child := range node.Children {
+		counts[child.XMLName.Local]++
+		if known[child.XMLName.Local] {
+			sequence = append(sequence, child.XMLName.Local)
+		}
+	}
+
+	if problem := element.content.matchAll(sequence); problem != "" {
+		*violations = append(*violations, Violation{Path: path, Message: problem})
+	}
+
+	seen := make(map[string]int)
+	for _, child := range node.Children {
+		name := child.XMLName.Local
+		childPath := path + "/" + name
+		if counts[name] > 1 {
+			seen[name]++
+			childPath = fmt.Sprintf("%s[%d]", childPath, seen[name])
+		}
+		s.validateNode(child, childPath, violations)
+	}
+}
+
+// names adds the element names the particle refers to
+func (p *particle) names(set map[string]bool) map[string]bool {
+	if p.kind == "element" {
+		set[p.name] = true
+	}
+	for _, child := range p.children {
+		child.names(set)
+	}
+	return set
+}
+
+// matchAll checks the sequence of child element names against the content
+// model. It returns an empty string when they match, or else describes the
+// first problem found.
+func (p *particle) matchAll(sequence []string) string {
+	m := &matcher{
+		sequence: sequence,
+		memo:     make(map[matchKey][]int),
+		missing:  make(map[string]bool),
+		failedAt: -1,
+	}
+
+	for _, end := range m.match(p, 0) {
+		if end == len(sequence) {
+			return ""
+		}
+	}
+
+	// Report the required element the furthest attempt was waiting for
+	if m.failedAt >= m.reached {
+		missing := make([]string, 0, len(m.missing))
+		for name := range m.missing {
+			missing = append(missing, name)
+		}
+		sort.Strings(missing)
+		problem := "missing required element " + missing[0]
+		if len(missing) > 1 {
+			problem = "missing one of " + strings.Join(missing, ", ")
+		}
+		// Name the element found instead, which shows misordered elements
+		if m.failedAt < len(sequence) {
+			problem += " before " + sequence[m.failedAt]
+		}
+		return problem
+	}
+	return fmt.Sprintf("unexpected element %s", sequence[m.reached])
+}
+
+// matchKey identifies a particle matched from a position of the sequence
+type matchKey struct {
+	particle *particle
+	position int
+}
+
+// matcher matches a sequence of element names against a content model,
+// following every way the model can consume them
+type matcher struct {
+	sequence []string
+	memo     map[matchKey][]int
+
+	// Furthest position consumed, and the furthest position where a required
+	// element was missing with the elements expected there
+	reached  int
+	failedAt int
+	missing  map[string]bool
+}
+
+// match returns every position the particle can end at when it starts
+// matching at the given position
+func (m *matcher) match(p *particle, position int) []int {
+	key := matchKey{p, position}
+	if ends, ok := m.memo[key]; ok {
+		return ends
+	}
+	m.memo[key] = nil // Guards against models that consume nothing
+
+	// Match the particle once, then again from each new end position until
+	// it reaches maxOccurs or stops advancing
+	var ends []int
+	if p.minOccurs == 0 {
+		ends = append(ends, position)
+	}
+	current := []int{position}
+	for count := 1; p.maxOccurs < 0 || count <= p.maxOccurs; count++ {
+		var next []int
+		for _, start := range current {
+			for _, end := range m.matchOnce(p, start, count <= p.minOccurs) {
+				if end > start || count <= p.minOccurs {
+					next = appendUnique(next, end)
+				}
+			}
+		}
+		if len(next) == 0 {
+			break
+		}
+		if count >= p.minOccurs {
+			for _, end := range next {
+				ends = appendUnique(ends, end)
+			}
+		}
+		current = next
+	}
+
+	m.memo[key] = ends
+	return ends
+}
+
+// matchOnce returns the end positions of a single occurrence of the particle
+func (m *matcher) matchOnce(p *particle, position int, required bool) []int {
+	switch p.kind {
+	case "element":
+		if position < len(m.sequence) && m.sequence[position] == p.name {
+			if position+1 > m.reached {
+				m.reached = position + 1
+			}
+			return []int{position + 1}
+		}
+		if required {
+			m.fail(position, p.name)
+		}
+		return nil
+
+	case "sequence":
+		current := []int{position}
+		for _, child := range p.children {
+			var next []int
+			for _, start := range current {
+				for _, end := range m.match(child, start) {
+					next = appendUnique(next, end)
+				}
+			}
+			if len(next) == 0 {
+				return nil
+			}
+			current = next
+		}
+		return current
+
+	case "choice":
+		if len(p.children) == 0 {
+			return []int{position}
+		}
+		var ends []int
+		for _, child := range p.children {
+			for _, end := range m.match(child, position) {
+				ends = appendUnique(ends, end)
+			}
+		}
+		return ends
+	}
+
+	return []int{position}
+}
+
+// fail records a required element missing at a position
+func (m *matcher) fail(position int, name string) {
+	if position > m.failedAt {
+		m.failedAt = position
+		m.missing = make(map[string]bool)
+	}
+	if position == m.failedAt {
+		m.missing[name] = true
+	}
+}
+
+// appendUnique appends a position unless it is already listed
+func appendUnique(positions []int, position int) []int {
+	for _, existing := range positions {
+		if existing == position {
+			return positions
+		}
+	}
+	return append(positions, position)
+}
+
+// check describes how a value breaks the facets, or returns an empty string
+func (f facets) check(value string) string {
+	if len(f.enumeration) > 0 {
+		allowed := false
+		for _, option := range f.enumeration {
+			if value == option {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Sprintf("value %q is not one of %s", value, strings.Join(f.enumeration, ", "))
+		}
+	}
+
+	length := utf8.RuneCountInString(value)
+	if f.maxLength >= 0 && length > f.maxLength {
+		return fmt.Sprintf("value is %d characters long, the maximum is %d", length, f.maxLength)
+	}
+	if f.minLength >= 0 && length < f.minLength {
+		return fmt.Sprintf("value is %d characters long, the minimum is %d", length, f.minLength)
+	}
+
+	return ""
+}
@@ -0,0 +1,217 @@
+package xml
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// loadTestSchema loads the MOS XSD bundled with the repository
+func loadTestSchema(t *testing.T) *Schema {
+	t.Helper()
+
+	schema, err := LoadSchema(filepath.Join("..", "..", "..", "res", "mosv4.xsd"))
+	if err != nil {
+		t.Fatalf("LoadSchema() error: %v", err)
+	}
+	return schema
+}
+
+// testHeader is the envelope header of the generated test messages
+var testHeader = MOSHeader{MosID: "openmos.test", NcsID: "ncs.test", MessageID: "42"}
+
+func TestGeneratedMessagesMatchSchema(t *testing.T) {
+	schema := loadTestSchema(t)
+
+	story := StoryInfo{
+		ID:       "STORY1",
+		Slug:     "Opening",
+		Number:   "A1",
+		Duration: "900",
+		Items: []ItemInfo{
+			{ID: "ITEM1", Slug: "Clip", Duration: "250", ObjectID: "OBJ1", MosID: "video.test", Channel: "A"},
+		},
+	}
+	object := MOSObj{
+		Timestamp: Now(),
+		Source:    "openmos.test",
+		ObjID:     "OBJ1",
+		ObjSlug:   "Fire",
+		ObjType:   "VIDEO",
+		ObjTB:     "25",
+		ObjRev:    "1",
+		ObjDur:    "250",
+		Status:    "READY",
+		ObjAir:    "READY",
+		CreatedBy: "editor",
+		Created:   Now(),
+		ChangedBy: "editor",
+		Changed:   Now(),
+	}
+
+	tests := []struct {
+		name    string
+		message MOSMessage
+	}{
+		{"CreateHeartbeat", CreateHeartbeat("openmos.test", "1")},
+		{"CreateHeartbeatResponse", CreateHeartbeatResponse("openmos.test", "1")},
+		{"CreateMOSAck", CreateMOSAck("openmos.test", "1", "ACK", "")},
+		{"CreateMOSAck NACK", CreateMOSAck("openmos.test", "1", "NACK", "Unknown running order")},
+		{"CreateROAck", CreateROAck("openmos.test", "1", "RO1", "OK")},
+		{"CreateROAckResults story", CreateROAckResults("openmos.test", "1", "RO1", "OK", []ROAckElement{
+			{StoryID: "STORY1", Status: "OK"},
+		})},
+		{"CreateROAckResults item", CreateROAckResults("openmos.test", "1", "RO1", "OK", []ROAckElement{
+			CreateROAckElement("STORY1", "ITEM1", "OBJ1", "A", "OK"),
+		})},
+		{"CreateROAckResults mixed", CreateROAckResults("openmos.test", "1", "RO1", "NACK", []ROAckElement{
+			CreateROAckElement("STORY1", "", "", "", "NACK"),
+			CreateROAckElement("STORY1", "ITEM1", "OBJ1", "A", "OK"),
+		})},
+		{"CreateRODelete", CreateRODelete("openmos.test", "1", "RO1")},
+		{"CreateRunningOrderList", CreateRunningOrderList("openmos.test", "1", []ROListItem{
+			{ID: "RO1", Slug: "Evening News", Channel: "A", Status: "READY"},
+		})},
+		{"CreateRunningOrderInfo", CreateRunningOrderInfo("openmos.test", "1", "RO1", "Evening News",
+			"A", "", "", "", []StoryInfo{story})},
+		{"roReplace", ROReplace{RunningOrderInfo: CreateRunningOrderInfo("openmos.test", "1", "RO1", "Evening News",
+			"", "", "", "", []StoryInfo{story})}},
+		{"roList", ROList{RunningOrderInfo: CreateRunningOrderInfo("openmos.test", "1", "RO1", "Evening News",
+			"", "", "", "", []StoryInfo{story})}},
+		{"listMachInfo", ListMachInfo{
+			Manufacturer:      "Airshift Media",
+			Model:             "OpenMOS",
+			ID:                "openmos.test",
+			Time:              Now(),
+			MOSRev:            "4.0",
+			SupportedProfiles: CreateSupportedProfiles("MOS", map[int]bool{0: true, 1: true, 2: true}),
+		}},
+		{"mosObj", object},
+		{"mosListAll", MOSListAll{Objects: []MOSObj{object}}},
+		{"mosObjList", MOSObjList{QueryID: "Q1", ListReturnStart: 1, ListReturnEnd: 1, ListReturnTotal: 1, List: &ObjList{Objects: []MOSObj{object}}}},
+		{"mosListSearchableSchema", MOSListSearchableSchema{MosSchema: "http://openmos.test:10543/schema/mosObj-search.xsd"}},
+		{"roElementStat", ROElementStat{Element: "ITEM", ROID: "RO1", StoryID: "STORY1", ItemID: "ITEM1", ObjID: "OBJ1", Status: "PLAY", Time: Now()}},
+		{"roStoryStat", ROStoryStat{ROID: "RO1", StoryID: "STORY1", Status: "PLAY", Time: Now()}},
+		{"roItemStat", ROItemStat{ROID: "RO1", StoryID: "STORY1", ItemID: "ITEM1", ObjID: "OBJ1", Status: "PLAY", Time: Now()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := GenerateMessage(WithHeader(tt.message, testHeader))
+			if err != nil {
+				t.Fatalf("GenerateMessage() error: %v", err)
+			}
+			if err := schema.ValidateMessage(data); err != nil {
+				t.Errorf("%v\n%s", err, data)
+			}
+		})
+	}
+}
+
+func TestCreateStoryResponseMatchesSchema(t *testing.T) {
+	data, err := CreateStoryResponse("1", "openmos.test", "ACK", "Story stored")
+	if err != nil {
+		t.Fatalf("CreateStoryResponse() error: %v", err)
+	}
+	if err := loadTestSchema(t).ValidateMessage(data); err != nil {
+		t.Errorf("%v\n%s", err, data)
+	}
+}
+
+func TestInvalidMessages(t *testing.T) {
+	schema := loadTestSchema(t)
+
+	tests := []struct {
+		name    string
+		payload string
+		path    string
+		message string
+	}{
+		{
+			name:    "missing required child",
+			payload: `<roCreate><roSlug>Evening News</roSlug></roCreate>`,
+			path:    "roCreate",
+			message: "missing required element roID before roSlug",
+		},
+		{
+			name:    "missing child of a repeated element",
+			payload: `<roCreate><roID>RO1</roID><roSlug>News</roSlug><story><storySlug>One</storySlug></story><story><storyID>S2</storyID><storySlug>Two</storySlug></story></roCreate>`,
+			path:    "roCreate/story[1]",
+			message: "missing required element storyID before storySlug",
+		},
+		{
+			name:    "tuple missing a required element next to a complete one",
+			payload: `<roAck><roID>RO1</roID><roStatus>OK</roStatus><storyID>S1</storyID><status>OK</status><storyID>S1</storyID><itemID>I1</itemID><objID>O1</objID><status>OK</status></roAck>`,
+			path:    "roAck",
+			message: "missing required element itemID before status",
+		},
+		{
+			name:    "elements out of sequence order",
+			payload: `<roAck><roStatus>OK</roStatus><roID>RO1</roID></roAck>`,
+			path:    "roAck",
+			message: "missing required element roID before roStatus",
+		},
+		{
+			name:    "element repeated beyond maxOccurs",
+			payload: `<roAck><roID>RO1</roID><roID>RO2</roID><roStatus>OK</roStatus></roAck>`,
+			path:    "roAck",
+		},
+		{
+			name:    "value outside the enumeration",
+			payload: `<mosObj><objID>OBJ1</objID><objSlug>Fire</objSlug><objType>HOLOGRAM</objType><objTB>25</objTB><objRev>1</objRev><objDur>250</objDur><status>READY</status><objAir>READY</objAir><createdBy>editor</createdBy><created>2026-01-01T00:00:00</created><changedBy>editor</changedBy><changed>2026-01-01T00:00:00</changed></mosObj>`,
+			path:    "mosObj/objType",
+		},
+		{
+			name:    "value longer than allowed",
+			payload: `<roCreate><roID>` + strings.Repeat("R", 200) + `</roID><roSlug>News</roSlug></roCreate>`,
+			path:    "roCreate/roID",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := schema.Validate([]byte(tt.payload))
+			if len(violations) == 0 {
+				t.Fatalf("no violation reported for %s", tt.payload)
+			}
+			for _, violation := range violations {
+				if violation.Path == tt.path && (tt.message == "" || violation.Message == tt.message) {
+					return
+				}
+			}
+			t.Errorf("violations %v do not include %s: %s", violations, tt.path, tt.message)
+		})
+	}
+}
+
+func TestUnknownElementsAreAccepted(t *testing.T) {
+	payload := `<roCreate><roID>RO1</roID><roSlug>News</roSlug><vendorExtension><anything/></vendorExtension></roCreate>`
+	if violations := loadTestSchema(t).Validate([]byte(payload)); len(violations) > 0 {
+		t.Errorf("unexpected violations: %v", violations)
+	}
+}
+
+func TestParserReportsSchemaViolations(t *testing.T) {
+	parser := NewMessageParser()
+	parser.SetSchema(loadTestSchema(t))
+	parser.AppendData([]byte(`<mos><mosID>openmos.test</mosID><ncsID>ncs.test</ncsID><messageID>7</messageID>` +
+		`<roCreate><roSlug>Evening News</roSlug></roCreate></mos>`))
+
+	// The message is still decoded so the caller may handle it in warn mode
+	message, _, err := parser.Parse()
+	if !errors.Is(err, ErrSchemaViolation) {
+		t.Fatalf("Parse() error = %v, want ErrSchemaViolation", err)
+	}
+	if message == nil || message.GetMessageType() != "roCreate" {
+		t.Fatalf("Parse() message = %v, want the decoded roCreate", message)
+	}
+
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("Parse() error %T is not a *ValidationError", err)
+	}
+	if invalid.MessageType != "roCreate" || invalid.Header.MessageID != "7" {
+		t.Errorf("ValidationError = %s with header %+v", invalid.MessageType, invalid.Header)
+	}
+}
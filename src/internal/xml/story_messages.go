@@ -2,6 +2,7 @@ package xml
 
 import (
 	"encoding/xml"
+	"strings"
 )
 
 // NCSReqStoryAction represents a request from NCS to perform an action on a story
@@ -11,6 +12,8 @@ type NCSReqStoryAction struct {
 	LeaseLock   string      `xml:"leaseLock,attr,omitempty"`
 	Username    string      `xml:"username,attr,omitempty"`
 	ROStorySend ROStorySend `xml:"roStorySend"`
+
+	MOSHeader `xml:"-"`
 }
 
 // GetMessageType returns the type of the message
@@ -18,17 +21,38 @@ func (a NCSReqStoryAction) GetMessageType() string {
 	return "ncsReqStoryAction"
 }
 
-// ROStorySend represents a story send operation
+// ROStorySend represents a story body sent by the NCS, either on its own
+// (Profile 4) or wrapped in ncsReqStoryAction
+// Format: <roStorySend><roID/><storyID/><storySlug/><storyNum/><storyBody/></roStorySend>
 type ROStorySend struct {
 	XMLName      xml.Name              `xml:"roStorySend"`
+	RequestID    string                `xml:"requestID,attr,omitempty"`
+	Timestamp    string                `xml:"timestamp,attr,omitempty"`
+	Source       string                `xml:"source,attr,omitempty"`
 	ROID         string                `xml:"roID"`
 	StoryID      string                `xml:"storyID"`
 	StorySlug    string                `xml:"storySlug,omitempty"`
 	StoryNum     string                `xml:"storyNum,omitempty"`
 	StoryBody    StoryBody             `xml:"storyBody"`
 	ExternalMeta []MosExternalMetadata `xml:"mosExternalMetadata,omitempty"`
+
+	MOSHeader `xml:"-"`
 }
 
+// GetMessageType returns the type of the message
+func (r ROStorySend) GetMessageType() string {
+	return "roStorySend"
+}
+
+// Types of the elements of a story paragraph
+const (
+	StoryElementText        = "TEXT"
+	StoryElementPI          = "PI"
+	StoryElementPresenter   = "PRESENTER"
+	StoryElementPresenterRR = "PRESENTER_RR"
+	StoryElementItem        = "ITEM"
+)
+
 // StoryBody represents the body content of a story
 type StoryBody struct {
 	XMLName    xml.Name         `xml:"storyBody"`
@@ -36,27 +60,165 @@ type StoryBody struct {
 	Paragraphs []StoryParagraph `xml:"p"`
 }
 
-// StoryParagraph represents a paragraph in a story body
+// UnmarshalXML decodes a story body. Presenters and items that older senders
+// place directly in the body, outside a <p>, are kept in a paragraph of their own.
+func (b *StoryBody) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	b.XMLName = start.Name
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "Read1stMEMasBody" {
+			b.ReadAsBody = attr.Value
+		}
+	}
+
+	loose := -1
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "p" {
+				var paragraph StoryParagraph
+				if err := d.DecodeElement(&paragraph, &t); err != nil {
+					return err
+				}
+				b.Paragraphs = append(b.Paragraphs, paragraph)
+				loose = -1
+				continue
+			}
+
+			if loose == -1 {
+				b.Paragraphs = append(b.Paragraphs, StoryParagraph{})
+				loose = len(b.Paragraphs) - 1
+			}
+			if err := b.Paragraphs[loose].decodeElement(d, t); err != nil {
+				return err
+			}
+
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// StoryParagraph represents a paragraph in a story body. Its text, producer
+// instructions, presenters and items are kept in script order.
 type StoryParagraph struct {
-	XMLName      xml.Name         `xml:"p"`
-	Content      string           `xml:",chardata"` // Plain text content
-	Instructions []StoryPI        `xml:"pi,omitempty"`
-	Presenters   []StoryPresenter `xml:"storyPresenter,omitempty"`
-	PresenterRRs []string         `xml:"storyPresenterRR,omitempty"`
-	Items        []StoryItem      `xml:"storyItem,omitempty"`
-	// Handle formatting tags like b, i, u if needed
+	Elements []StoryElement
 }
 
-// StoryPI represents producer instructions in a story paragraph
-type StoryPI struct {
-	XMLName xml.Name `xml:"pi"`
-	Content string   `xml:",chardata"`
+// StoryElement is a run of text or an element embedded in a paragraph.
+// Text holds the text, instruction, presenter name or read rate.
+type StoryElement struct {
+	Type string
+	Text string
+	Item *StoryItem
+}
+
+// UnmarshalXML decodes a paragraph. Formatting such as <b>, <i>, <u> and
+// <em> is flattened into the surrounding text.
+func (p *StoryParagraph) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var text strings.Builder
+
+	flush := func() {
+		if content := normalizeText(text.String()); content != "" {
+			p.Elements = append(p.Elements, StoryElement{Type: StoryElementText, Text: content})
+		}
+		text.Reset()
+	}
+
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.CharData:
+			text.Write(t)
+
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "pi", "storyPresenter", "storyPresenterRR", "storyItem":
+				flush()
+				if err := p.decodeElement(d, t); err != nil {
+					return err
+				}
+			case "tab":
+				text.WriteString(" ")
+				if err := d.Skip(); err != nil {
+					return err
+				}
+			default:
+				inner, err := innerText(d)
+				if err != nil {
+					return err
+				}
+				text.WriteString(inner)
+			}
+
+		case xml.EndElement:
+			flush()
+			return nil
+		}
+	}
+}
+
+// decodeElement decodes an instruction, presenter or item into the paragraph
+func (p *StoryParagraph) decodeElement(d *xml.Decoder, start xml.StartElement) error {
+	if start.Name.Local == "storyItem" {
+		var item StoryItem
+		if err := d.DecodeElement(&item, &start); err != nil {
+			return err
+		}
+		p.Elements = append(p.Elements, StoryElement{Type: StoryElementItem, Item: &item})
+		return nil
+	}
+
+	types := map[string]string{
+		"pi":               StoryElementPI,
+		"storyPresenter":   StoryElementPresenter,
+		"storyPresenterRR": StoryElementPresenterRR,
+	}
+	elementType, ok := types[start.Name.Local]
+	if !ok {
+		return d.Skip()
+	}
+
+	text, err := innerText(d)
+	if err != nil {
+		return err
+	}
+	p.Elements = append(p.Elements, StoryElement{Type: elementType, Text: normalizeText(text)})
+	return nil
+}
+
+// innerText returns the text of the current element and its children
+func innerText(d *xml.Decoder) (string, error) {
+	var text strings.Builder
+	for depth := 1; depth > 0; {
+		token, err := d.Token()
+		if err != nil {
+			return "", err
+		}
+
+		switch t := token.(type) {
+		case xml.CharData:
+			text.Write(t)
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+	}
+	return text.String(), nil
 }
 
-// StoryPresenter represents a presenter assignment in a story
-type StoryPresenter struct {
-	XMLName xml.Name `xml:"storyPresenter"`
-	Name    string   `xml:",chardata"`
+// normalizeText collapses runs of whitespace in script text
+func normalizeText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
 }
 
 // StoryItem represents a media item within a story paragraph
@@ -66,6 +228,8 @@ type StoryItem struct {
 	ItemSlug          string                `xml:"itemSlug,omitempty"`
 	ObjID             string                `xml:"objID"`
 	MosID             string                `xml:"mosID"`
+	MosAbstract       string                `xml:"mosAbstract,omitempty"`
+	ItemChannel       string                `xml:"itemChannel,omitempty"`
 	ItemEdStart       int                   `xml:"itemEdStart,omitempty"`
 	ItemEdDur         int                   `xml:"itemEdDur,omitempty"`
 	ItemUserTimingDur int                   `xml:"itemUserTimingDur,omitempty"`
@@ -167,7 +167,8 @@ func main() {
 		}
 	}()
 
-	log.Infof("OpenMOS server is running on %s", cfg.GetServerAddress())
+	log.Infof("OpenMOS server is running on %s (upper port %d, query port %d)",
+		cfg.GetServerAddress(), cfg.Server.UpperPort, cfg.Server.QueryPort)
 
 	// Wait for shutdown signal
 	sig := <-sigCh
@@ -175,6 +176,7 @@ func main() {
 
 	// Cancel the server context to start the graceful shutdown
 	cancel()
+	mosService.Close()
 
 	// Flush Sentry events before exiting
 	defer sentry.Flush(2 * time.Second)
@@ -0,0 +1,72 @@
+package mos_test
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+
+	"airshift/openmos/pkg/mos"
+)
+
+// TallyState is a vendor-specific message reporting the tally of a camera
+type TallyState struct {
+	XMLName xml.Name `xml:"vndTallyState"`
+	Camera  string   `xml:"camera"`
+	State   string   `xml:"state"`
+
+	mos.Header `xml:"-"`
+}
+
+// GetMessageType returns the type of the message
+func (t TallyState) GetMessageType() string {
+	return "vndTallyState"
+}
+
+func handleTallyState(ctx context.Context, c *mos.Conn, tally TallyState) error {
+	fmt.Printf("%s is %s (message %s)\n", tally.Camera, tally.State, tally.MessageID)
+	return nil
+}
+
+func Example() {
+	mos.RegisterMessage("vndTallyState", TallyState{})
+
+	registry := mos.NewRegistry()
+	registry.Use(func(route mos.Route, next mos.HandlerFunc) mos.HandlerFunc {
+		return func(ctx context.Context, c *mos.Conn, message mos.Message) error {
+			fmt.Printf("%s on the %s port\n", route.MessageType, route.Ports[0])
+			return next(ctx, c, message)
+		}
+	})
+	registry.Handle(mos.Route{
+		MessageType: "vndTallyState",
+		Ports:       []string{mos.PortLower},
+		Handler:     mos.Handler(handleTallyState),
+	})
+
+	message, err := mos.Parse([]byte(`<mos><mosID>openmos</mosID><ncsID>ncs</ncsID><messageID>3</messageID>` +
+		`<vndTallyState><camera>CAM1</camera><state>LIVE</state></vndTallyState></mos>`))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	if err := registry.Dispatch(context.Background(), nil, message); err != nil {
+		fmt.Println(err)
+	}
+
+	// Output:
+	// vndTallyState on the lower port
+	// CAM1 is LIVE (message 3)
+}
+
+func ExampleExtend() {
+	// Called from init, before the server is created
+	mos.RegisterMessage("vndTallyState", TallyState{})
+	mos.Extend(func(registry *mos.Registry) {
+		registry.Handle(mos.Route{
+			MessageType: "vndTallyState",
+			Ports:       []string{mos.PortLower},
+			Handler:     mos.Handler(handleTallyState),
+		})
+	})
+}
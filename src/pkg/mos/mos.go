@@ -0,0 +1,86 @@
+// Package mos is the public API for extending OpenMOS with vendor-specific
+// messages: their decoders, their handlers and the middleware around them.
+package mos
+
+import (
+	"context"
+	"fmt"
+
+	"airshift/openmos/internal/server"
+	"airshift/openmos/internal/xml"
+)
+
+// Message is a MOS message, the root element inside the <mos> envelope
+type Message = xml.MOSMessage
+
+// Header is the <mos> envelope of a message. Message types embed it to
+// receive the envelope of the messages they decode.
+type Header = xml.MOSHeader
+
+// MessageDecoder decodes the payload of a message
+type MessageDecoder = xml.MessageDecoder
+
+// Conn is a connection to an NCS or a MOS device
+type Conn = server.ClientConnection
+
+// HandlerFunc handles a message received on a connection
+type HandlerFunc = server.HandlerFunc
+
+// Middleware wraps the handler of a route
+type Middleware = server.Middleware
+
+// Route binds a message type to its handler, MOS profile and ports
+type Route = server.Route
+
+// Registry holds the routes and middleware of a server
+type Registry = server.Registry
+
+// MOS ports named in Route.Ports
+const (
+	PortLower = server.PortLower
+	PortUpper = server.PortUpper
+	PortQuery = server.PortQuery
+)
+
+// RegisterMessage registers a message type decoded with encoding/xml into a
+// value of the prototype's type, which embeds Header
+func RegisterMessage(messageType string, prototype Message) {
+	xml.RegisterMessage(messageType, prototype)
+}
+
+// RegisterDecoder registers the decoder of the messages with the given root
+// element, replacing any previous one
+func RegisterDecoder(messageType string, decode MessageDecoder) {
+	xml.RegisterDecoder(messageType, decode)
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return server.NewRegistry()
+}
+
+// Extend registers a function adding routes and middleware to the registry of
+// every server created afterwards, after the built-in ones
+func Extend(extension func(*Registry)) {
+	server.Extend(extension)
+}
+
+// Handler adapts a function taking a concrete message type to a HandlerFunc
+func Handler[T Message](handle func(ctx context.Context, c *Conn, message T) error) HandlerFunc {
+	return func(ctx context.Context, c *Conn, message Message) error {
+		msg, ok := message.(T)
+		if !ok {
+			return fmt.Errorf("unexpected message type: %T", message)
+		}
+		return handle(ctx, c, msg)
+	}
+}
+
+// Parse decodes a complete <mos> message with the registered decoders
+func Parse(data []byte) (Message, error) {
+	parser := xml.NewMessageParser()
+	parser.AppendData(data)
+
+	message, _, err := parser.Parse()
+	return message, err
+}